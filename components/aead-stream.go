@@ -0,0 +1,303 @@
+package components
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+/**
+ * 分段流格式：
+ * 头部：version(1) | alg(1) | kidLen(1) | kid | chunkSize(4) | prefixLen(1) | noncePrefix
+ * 分段：ciphertext | tag，除最后一段外明文长度均为chunkSize
+ * 每段nonce = noncePrefix | counter(4) | last(1)，头部作为附加数据参与认证，
+ * 因此分段被截断、重排或替换都会导致解密失败
+ */
+
+const (
+	StreamVersion1 uint8 = 1
+)
+
+const (
+	DefaultStreamChunkSize = 64 << 10
+	MaxStreamChunkSize     = 16 << 20
+)
+
+const (
+	streamNonceSuffix = 5
+)
+
+var (
+	ErrStreamTruncated = errors.New(`aead stream truncated`)
+	ErrStreamChunkSize = errors.New(`aead stream chunk size out of range`)
+	ErrStreamTooLarge  = errors.New(`aead stream too many chunks`)
+	ErrStreamClosed    = errors.New(`aead stream closed`)
+)
+
+type streamWriter struct {
+	w       io.Writer
+	key     aeadKey
+	header  []byte
+	prefix  []byte
+	nonce   []byte
+	buf     []byte
+	out     []byte
+	counter uint32
+	started bool
+	closed  bool
+}
+
+// NewEncryptWriter 使用主密钥创建分段加密Writer，chunkSize<=0时使用DefaultStreamChunkSize，必须调用Close写入最后一段
+func (a *Aead) NewEncryptWriter(w io.Writer, chunkSize int) (io.WriteCloser, error) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultStreamChunkSize
+	}
+
+	if chunkSize > MaxStreamChunkSize {
+		return nil, ErrStreamChunkSize
+	}
+
+	a.mutex.RLock()
+	kid := a.primary
+	key := a.keys[kid]
+	a.mutex.RUnlock()
+
+	nonceSize := key.aead.NonceSize()
+	if nonceSize <= streamNonceSuffix {
+		return nil, ErrAeadAlgorithm
+	}
+
+	prefix := make([]byte, nonceSize-streamNonceSuffix)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, 8+len(kid)+len(prefix))
+	header = append(header, StreamVersion1, key.alg, byte(len(kid)))
+	header = append(header, kid...)
+	header = binary.BigEndian.AppendUint32(header, uint32(chunkSize))
+	header = append(header, byte(len(prefix)))
+	header = append(header, prefix...)
+
+	return &streamWriter{
+		w:      w,
+		key:    key,
+		header: header,
+		prefix: prefix,
+		nonce:  make([]byte, nonceSize),
+		buf:    make([]byte, 0, chunkSize),
+		out:    make([]byte, 0, chunkSize+key.aead.Overhead()),
+	}, nil
+}
+
+func (sw *streamWriter) Write(p []byte) (n int, err error) {
+	if sw.closed {
+		return 0, ErrStreamClosed
+	}
+
+	for len(p) > 0 {
+		// 缓冲区已满且还有数据，说明不是最后一段
+		if len(sw.buf) == cap(sw.buf) {
+			if err = sw.flush(false); err != nil {
+				return n, err
+			}
+		}
+
+		size := copy(sw.buf[len(sw.buf):cap(sw.buf)], p)
+		sw.buf = sw.buf[:len(sw.buf)+size]
+		p = p[size:]
+		n += size
+	}
+
+	return n, nil
+}
+
+// Close 写入最后一段，不会关闭底层Writer
+func (sw *streamWriter) Close() error {
+	if sw.closed {
+		return nil
+	}
+
+	err := sw.flush(true)
+	sw.closed = true
+	return err
+}
+
+func (sw *streamWriter) flush(last bool) error {
+	if !sw.started {
+		if _, err := sw.w.Write(sw.header); err != nil {
+			return err
+		}
+		sw.started = true
+	}
+
+	if sw.counter == math.MaxUint32 {
+		return ErrStreamTooLarge
+	}
+
+	streamNonce(sw.nonce, sw.prefix, sw.counter, last)
+	sw.out = sw.key.aead.Seal(sw.out[:0], sw.nonce, sw.buf, sw.header)
+	if _, err := sw.w.Write(sw.out); err != nil {
+		return err
+	}
+
+	sw.counter++
+	sw.buf = sw.buf[:0]
+	return nil
+}
+
+type streamReader struct {
+	r       *bufio.Reader
+	key     aeadKey
+	header  []byte
+	prefix  []byte
+	nonce   []byte
+	in      []byte
+	buf     []byte
+	plain   []byte
+	counter uint32
+	done    bool
+	err     error
+}
+
+// NewDecryptReader 读取流头部并根据kid选择密钥，返回分段解密Reader
+func (a *Aead) NewDecryptReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	fixed := make([]byte, 3)
+	if _, err := io.ReadFull(br, fixed); err != nil {
+		return nil, streamEOF(err)
+	}
+
+	if fixed[0] != StreamVersion1 {
+		return nil, ErrAeadVersion
+	}
+
+	kidLen := int(fixed[2])
+	if kidLen < 1 {
+		return nil, ErrAeadEnvelope
+	}
+
+	rest := make([]byte, kidLen+5)
+	if _, err := io.ReadFull(br, rest); err != nil {
+		return nil, streamEOF(err)
+	}
+
+	prefix := make([]byte, int(rest[kidLen+4]))
+	if _, err := io.ReadFull(br, prefix); err != nil {
+		return nil, streamEOF(err)
+	}
+
+	kid := string(rest[:kidLen])
+	chunkSize := int(binary.BigEndian.Uint32(rest[kidLen : kidLen+4]))
+	if chunkSize < 1 || chunkSize > MaxStreamChunkSize {
+		return nil, ErrStreamChunkSize
+	}
+
+	a.mutex.RLock()
+	key, exists := a.keys[kid]
+	a.mutex.RUnlock()
+
+	if !exists {
+		return nil, ErrAeadKeyNotFound
+	}
+
+	if key.alg != fixed[1] {
+		return nil, ErrAeadAlgorithm
+	}
+
+	if len(prefix)+streamNonceSuffix != key.aead.NonceSize() {
+		return nil, ErrAeadEnvelope
+	}
+
+	header := make([]byte, 0, len(fixed)+len(rest)+len(prefix))
+	header = append(header, fixed...)
+	header = append(header, rest...)
+	header = append(header, prefix...)
+
+	return &streamReader{
+		r:      br,
+		key:    key,
+		header: header,
+		prefix: prefix,
+		nonce:  make([]byte, key.aead.NonceSize()),
+		in:     make([]byte, chunkSize+key.aead.Overhead()),
+		buf:    make([]byte, 0, chunkSize),
+	}, nil
+}
+
+func (sr *streamReader) Read(p []byte) (n int, err error) {
+	for len(sr.plain) == 0 {
+		if sr.err != nil {
+			return 0, sr.err
+		}
+
+		if sr.done {
+			return 0, io.EOF
+		}
+
+		sr.err = sr.next()
+	}
+
+	n = copy(p, sr.plain)
+	sr.plain = sr.plain[n:]
+	return n, nil
+}
+
+func (sr *streamReader) next() error {
+	size, err := io.ReadFull(sr.r, sr.in)
+	switch {
+	case err == io.EOF:
+		// 没有读到标记为最后一段的分段
+		return ErrStreamTruncated
+	case err == io.ErrUnexpectedEOF:
+		sr.done = true
+	case err != nil:
+		return err
+	default:
+		if _, err = sr.r.Peek(1); err == io.EOF {
+			sr.done = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if size < sr.key.aead.Overhead() {
+		return ErrStreamTruncated
+	}
+
+	streamNonce(sr.nonce, sr.prefix, sr.counter, sr.done)
+	plain, err := sr.key.aead.Open(sr.buf[:0], sr.nonce, sr.in[:size], sr.header)
+	if err != nil {
+		return ErrAeadDecrypt
+	}
+
+	if sr.counter == math.MaxUint32 {
+		return ErrStreamTooLarge
+	}
+
+	sr.counter++
+	sr.plain = plain
+	return nil
+}
+
+func streamNonce(nonce, prefix []byte, counter uint32, last bool) {
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[len(prefix):], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	} else {
+		nonce[len(nonce)-1] = 0
+	}
+}
+
+func streamEOF(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrStreamTruncated
+	}
+
+	return err
+}
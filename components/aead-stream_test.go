@@ -0,0 +1,97 @@
+package components
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+)
+
+func TestAead_NewDecryptReader(t *testing.T) {
+	a, err := NewAesGcm("k1", []byte("b#%*N130js&@1nuc"))
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	for _, size := range []int{0, 1, 64, 100, 128, 1000} {
+		data := make([]byte, size)
+		_, _ = rand.Read(data)
+
+		var buf bytes.Buffer
+		w, err := a.NewEncryptWriter(&buf, 64)
+		if err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+
+		for offset := 0; offset < len(data); offset += 7 {
+			end := offset + 7
+			if end > len(data) {
+				end = len(data)
+			}
+
+			if _, err = w.Write(data[offset:end]); err != nil {
+				t.Fatalf("want nil, got %v", err)
+			}
+		}
+
+		if err = w.Close(); err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+
+		r, err := a.NewDecryptReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+
+		plain, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("size %d want nil, got %v", size, err)
+		}
+
+		if !bytes.Equal(plain, data) {
+			t.Fatalf("size %d want equal, got different", size)
+		}
+	}
+}
+
+func TestAead_NewDecryptReaderTampered(t *testing.T) {
+	a, _ := NewAesGcm("k1", []byte("b#%*N130js&@1nuc"))
+
+	var buf bytes.Buffer
+	w, _ := a.NewEncryptWriter(&buf, 16)
+	_, _ = w.Write(bytes.Repeat([]byte("0123456789abcdef"), 4))
+	_ = w.Close()
+
+	var (
+		stream     = buf.Bytes()
+		chunkLen   = 16 + 16
+		headerLen  = len(stream) - 4*chunkLen
+		truncated  = stream[:headerLen+3*chunkLen]
+		reordered  = append([]byte{}, stream[:headerLen]...)
+		headerOnly = stream[:headerLen]
+	)
+
+	reordered = append(reordered, stream[headerLen+chunkLen:headerLen+2*chunkLen]...)
+	reordered = append(reordered, stream[headerLen:headerLen+chunkLen]...)
+	reordered = append(reordered, stream[headerLen+2*chunkLen:]...)
+
+	cases := map[string]struct {
+		data []byte
+		want error
+	}{
+		"truncated":   {truncated, ErrAeadDecrypt},
+		"reordered":   {reordered, ErrAeadDecrypt},
+		"header only": {headerOnly, ErrStreamTruncated},
+	}
+
+	for name, c := range cases {
+		r, err := a.NewDecryptReader(bytes.NewReader(c.data))
+		if err != nil {
+			t.Fatalf("%s want nil, got %v", name, err)
+		}
+
+		if _, err = io.ReadAll(r); err != c.want {
+			t.Fatalf("%s want %v, got %v", name, c.want, err)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package components
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"sync"
+)
+
+/**
+ * 信封格式：
+ * version(1) | alg(1) | kidLen(1) | kid | nonceLen(1) | nonce | ciphertext | tag
+ * version、alg、kid、nonce组成的头部同时作为附加数据参与认证
+ */
+
+const (
+	EnvelopeVersion1 uint8 = 1
+)
+
+const (
+	AeadAesGcm uint8 = 1
+)
+
+const (
+	maxKidLength = 0xff
+)
+
+var (
+	ErrAeadEnvelope    = errors.New(`invalid aead envelope`)
+	ErrAeadVersion     = errors.New(`unsupported aead envelope version`)
+	ErrAeadAlgorithm   = errors.New(`unsupported aead algorithm`)
+	ErrAeadKeyNotFound = errors.New(`aead key not found`)
+	ErrAeadKid         = errors.New(`aead kid length must be [1, 255]`)
+	ErrAeadDecrypt     = errors.New(`aead decrypt error`)
+)
+
+// AeadFactory 根据密钥创建cipher.AEAD
+type AeadFactory func(key []byte) (cipher.AEAD, error)
+
+var (
+	aeadMutex     sync.RWMutex
+	aeadFactories = map[uint8]AeadFactory{
+		AeadAesGcm: newAesGcm,
+	}
+)
+
+// RegisterAead 注册AEAD算法，如golang.org/x/crypto/chacha20poly1305.NewX
+func RegisterAead(alg uint8, factory AeadFactory) {
+	aeadMutex.Lock()
+	defer aeadMutex.Unlock()
+
+	aeadFactories[alg] = factory
+}
+
+func newAesGcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Envelope 解析后的密文信封
+type Envelope struct {
+	Version    uint8
+	Alg        uint8
+	Kid        string
+	Nonce      []byte
+	Ciphertext []byte
+	header     []byte
+}
+
+// ParseEnvelope 解析密文信封，Ciphertext包含末尾的tag
+func ParseEnvelope(data []byte) (*Envelope, error) {
+	if len(data) < 4 {
+		return nil, ErrAeadEnvelope
+	}
+
+	if data[0] != EnvelopeVersion1 {
+		return nil, ErrAeadVersion
+	}
+
+	kidLen := int(data[2])
+	offset := 3 + kidLen
+	if kidLen < 1 || len(data) < offset+1 {
+		return nil, ErrAeadEnvelope
+	}
+
+	nonceLen := int(data[offset])
+	if len(data) < offset+1+nonceLen {
+		return nil, ErrAeadEnvelope
+	}
+
+	header := data[:offset+1+nonceLen]
+	return &Envelope{
+		Version:    data[0],
+		Alg:        data[1],
+		Kid:        string(data[3:offset]),
+		Nonce:      data[offset+1 : offset+1+nonceLen],
+		Ciphertext: data[offset+1+nonceLen:],
+		header:     header,
+	}, nil
+}
+
+type aeadKey struct {
+	alg  uint8
+	aead cipher.AEAD
+}
+
+// Aead 带密钥轮换的AEAD加密，使用主密钥加密，根据信封中的kid选择密钥解密
+type Aead struct {
+	mutex   sync.RWMutex
+	primary string
+	keys    map[string]aeadKey
+}
+
+// NewAead 实例化Aead，kid对应的密钥为主密钥
+func NewAead(kid string, alg uint8, key []byte) (a *Aead, err error) {
+	a = &Aead{
+		keys: make(map[string]aeadKey, 1),
+	}
+
+	if err = a.AddKey(kid, alg, key); err != nil {
+		return nil, err
+	}
+
+	a.primary = kid
+	return a, nil
+}
+
+// NewAesGcm 实例化AES-GCM加密，key长度为16、24或32
+func NewAesGcm(kid string, key []byte) (a *Aead, err error) {
+	return NewAead(kid, AeadAesGcm, key)
+}
+
+// AddKey 添加密钥，已存在的kid会被覆盖
+func (a *Aead) AddKey(kid string, alg uint8, key []byte) error {
+	if len(kid) < 1 || len(kid) > maxKidLength {
+		return ErrAeadKid
+	}
+
+	aeadMutex.RLock()
+	factory, exists := aeadFactories[alg]
+	aeadMutex.RUnlock()
+
+	if !exists {
+		return ErrAeadAlgorithm
+	}
+
+	aead, err := factory(key)
+	if err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	a.keys[kid] = aeadKey{
+		alg:  alg,
+		aead: aead,
+	}
+	a.mutex.Unlock()
+
+	return nil
+}
+
+// RemoveKey 移除密钥，不能移除主密钥
+func (a *Aead) RemoveKey(kid string) (ok bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if kid == a.primary {
+		return false
+	}
+
+	if _, exists := a.keys[kid]; !exists {
+		return false
+	}
+
+	delete(a.keys, kid)
+	return true
+}
+
+// UsePrimary 设置加密使用的主密钥
+func (a *Aead) UsePrimary(kid string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if _, exists := a.keys[kid]; !exists {
+		return ErrAeadKeyNotFound
+	}
+
+	a.primary = kid
+	return nil
+}
+
+// Primary 主密钥id
+func (a *Aead) Primary() string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.primary
+}
+
+// Encrypt 使用主密钥和随机nonce加密，返回信封
+func (a *Aead) Encrypt(plain, additional []byte) (envelope []byte, err error) {
+	a.mutex.RLock()
+	kid := a.primary
+	key := a.keys[kid]
+	a.mutex.RUnlock()
+
+	nonceSize := key.aead.NonceSize()
+	header := make([]byte, 0, 4+len(kid)+nonceSize)
+	header = append(header, EnvelopeVersion1, key.alg, byte(len(kid)))
+	header = append(header, kid...)
+	header = append(header, byte(nonceSize))
+
+	nonce := make([]byte, nonceSize)
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	header = append(header, nonce...)
+
+	envelope = make([]byte, len(header), len(header)+len(plain)+key.aead.Overhead())
+	copy(envelope, header)
+
+	return key.aead.Seal(envelope, nonce, plain, aeadAdditional(header, additional)), nil
+}
+
+// Decrypt 解密信封，additional需与加密时一致
+func (a *Aead) Decrypt(envelope, additional []byte) (plain []byte, err error) {
+	env, err := ParseEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mutex.RLock()
+	key, exists := a.keys[env.Kid]
+	a.mutex.RUnlock()
+
+	if !exists {
+		return nil, ErrAeadKeyNotFound
+	}
+
+	if key.alg != env.Alg {
+		return nil, ErrAeadAlgorithm
+	}
+
+	if len(env.Nonce) != key.aead.NonceSize() || len(env.Ciphertext) < key.aead.Overhead() {
+		return nil, ErrAeadEnvelope
+	}
+
+	plain, err = key.aead.Open(nil, env.Nonce, env.Ciphertext, aeadAdditional(env.header, additional))
+	if err != nil {
+		return nil, ErrAeadDecrypt
+	}
+
+	return plain, nil
+}
+
+func aeadAdditional(header, additional []byte) []byte {
+	if len(additional) == 0 {
+		return header
+	}
+
+	data := make([]byte, 0, len(header)+len(additional))
+	data = append(data, header...)
+	return append(data, additional...)
+}
@@ -0,0 +1,92 @@
+package components
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/grpc-boot/base/v3/utils"
+)
+
+func TestAead_Decrypt(t *testing.T) {
+	a, err := NewAesGcm("k1", []byte("b#%*N130js&@1nucb#%*N130js&@1nuc"))
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	data := []byte("撒旦法#$%")
+	first, err := a.Encrypt(data, []byte("user:1"))
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	second, _ := a.Encrypt(data, []byte("user:1"))
+	if bytes.Equal(first, second) {
+		t.Fatal("want different ciphertext, got same")
+	}
+
+	t.Logf("base64url: %s", utils.Base64UrlEncode(first))
+
+	plain, err := a.Decrypt(first, []byte("user:1"))
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if !bytes.Equal(plain, data) {
+		t.Fatalf("want %s, got %s", data, plain)
+	}
+
+	if _, err = a.Decrypt(first, []byte("user:2")); err != ErrAeadDecrypt {
+		t.Fatalf("want %v, got %v", ErrAeadDecrypt, err)
+	}
+
+	tampered := append([]byte{}, first...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err = a.Decrypt(tampered, []byte("user:1")); err != ErrAeadDecrypt {
+		t.Fatalf("want %v, got %v", ErrAeadDecrypt, err)
+	}
+
+	env, err := ParseEnvelope(first)
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if env.Kid != "k1" || env.Alg != AeadAesGcm || len(env.Nonce) != 12 {
+		t.Fatalf("want k1/%d/12, got %s/%d/%d", AeadAesGcm, env.Kid, env.Alg, len(env.Nonce))
+	}
+}
+
+func TestAead_UsePrimary(t *testing.T) {
+	a, _ := NewAesGcm("k1", []byte("b#%*N130js&@1nuc"))
+	old, _ := a.Encrypt([]byte("old"), nil)
+
+	if err := a.AddKey("k2", AeadAesGcm, []byte("3a#%*N130js&@18h3a#%*N130js&@18h")); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if err := a.UsePrimary("k2"); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	current, _ := a.Encrypt([]byte("new"), nil)
+	env, _ := ParseEnvelope(current)
+	if env.Kid != "k2" {
+		t.Fatalf("want k2, got %s", env.Kid)
+	}
+
+	plain, err := a.Decrypt(old, nil)
+	if err != nil || string(plain) != "old" {
+		t.Fatalf("want old, got %s %v", plain, err)
+	}
+
+	if a.RemoveKey("k2") {
+		t.Fatal("want false, got true")
+	}
+
+	if !a.RemoveKey("k1") {
+		t.Fatal("want true, got false")
+	}
+
+	if _, err = a.Decrypt(old, nil); err != ErrAeadKeyNotFound {
+		t.Fatalf("want %v, got %v", ErrAeadKeyNotFound, err)
+	}
+}
@@ -0,0 +1,389 @@
+package components
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/grpc-boot/base/v3/kind"
+
+	"go.uber.org/atomic"
+)
+
+var (
+	errPickRelease = errors.New("balancer release")
+)
+
+// Picker 负载均衡选择器，done必须在请求结束时调用，err不为nil时计为失败
+type Picker interface {
+	// Pick 选择一个server
+	Pick() (server kind.CanHash, done PickDone, err error)
+	// Store 替换servers，相同HashCode的server保留统计数据
+	Store(servers ...kind.CanHash)
+	// Length 获取servers长度
+	Length() int
+	// Stats server统计
+	Stats() []BalancerStat
+}
+
+// PickDone 使用请求结果调用，只有第一次调用生效
+type PickDone func(err error)
+
+// Release 结束请求但不记录延迟和结果，用于调用方取消等不能反映server状态的情况
+func (pd PickDone) Release() {
+	pd(errPickRelease)
+}
+
+// Weighted 带权重的server，没有实现该接口的server权重为1
+type Weighted interface {
+	Weight() int
+}
+
+// BalancerStat server统计
+type BalancerStat struct {
+	Server   kind.CanHash
+	Weight   int
+	Inflight int64
+	Latency  time.Duration
+	Failures int
+	Ejected  bool
+}
+
+// BalancerOption 负载均衡选项
+type BalancerOption func(opts *balancerOptions)
+
+type balancerOptions struct {
+	maxFails  int
+	ejectTime time.Duration
+	decay     time.Duration
+}
+
+// WithMaxFails 连续失败maxFails次后摘除server，0表示不摘除，默认5
+func WithMaxFails(maxFails int) BalancerOption {
+	return func(opts *balancerOptions) {
+		opts.maxFails = maxFails
+	}
+}
+
+// WithEjectTime 摘除时长，到期后重新参与选择，默认30秒
+func WithEjectTime(ejectTime time.Duration) BalancerOption {
+	return func(opts *balancerOptions) {
+		opts.ejectTime = ejectTime
+	}
+}
+
+// WithEwmaDecay 延迟EWMA的衰减时间，默认10秒
+func WithEwmaDecay(decay time.Duration) BalancerOption {
+	return func(opts *balancerOptions) {
+		opts.decay = decay
+	}
+}
+
+type balancerNode struct {
+	inflight atomic.Int64
+
+	// mutex 保护以下字段，server和weight在Store时可能被替换
+	mutex        sync.Mutex
+	server       kind.CanHash
+	weight       int
+	current      int
+	latency      float64
+	lastDone     time.Time
+	failures     int
+	ejectedUntil time.Time
+}
+
+// balancer 各选择器共享的server列表和被动健康检查
+type balancer struct {
+	mutex sync.RWMutex
+	nodes []*balancerNode
+	opts  balancerOptions
+	now   func() time.Time
+}
+
+func newBalancer(servers []kind.CanHash, opts []BalancerOption) *balancer {
+	b := &balancer{
+		opts: balancerOptions{
+			maxFails:  5,
+			ejectTime: time.Second * 30,
+			decay:     time.Second * 10,
+		},
+		now: time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(&b.opts)
+	}
+
+	b.Store(servers...)
+	return b
+}
+
+func (b *balancer) Store(servers ...kind.CanHash) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	old := make(map[uint32]*balancerNode, len(b.nodes))
+	for _, node := range b.nodes {
+		old[node.server.HashCode()] = node
+	}
+
+	nodes := make([]*balancerNode, 0, len(servers))
+	for _, server := range servers {
+		weight := 1
+		if w, ok := server.(Weighted); ok {
+			weight = w.Weight()
+		}
+
+		if weight <= 0 {
+			continue
+		}
+
+		if node, exists := old[server.HashCode()]; exists {
+			node.mutex.Lock()
+			node.server, node.weight = server, weight
+			node.mutex.Unlock()
+			nodes = append(nodes, node)
+			continue
+		}
+
+		nodes = append(nodes, &balancerNode{
+			server: server,
+			weight: weight,
+		})
+	}
+
+	b.nodes = nodes
+}
+
+func (b *balancer) Length() int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return len(b.nodes)
+}
+
+func (b *balancer) Stats() []BalancerStat {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	now := b.now()
+	stats := make([]BalancerStat, 0, len(b.nodes))
+	for _, node := range b.nodes {
+		node.mutex.Lock()
+		stats = append(stats, BalancerStat{
+			Server:   node.server,
+			Weight:   node.weight,
+			Inflight: node.inflight.Load(),
+			Latency:  time.Duration(node.latency),
+			Failures: node.failures,
+			Ejected:  node.ejectedUntil.After(now),
+		})
+		node.mutex.Unlock()
+	}
+
+	return stats
+}
+
+// healthy 未被摘除的server，全部被摘除时返回所有server，避免无server可用
+func (b *balancer) healthy() []*balancerNode {
+	var (
+		now   = b.now()
+		nodes = make([]*balancerNode, 0, len(b.nodes))
+	)
+
+	for _, node := range b.nodes {
+		node.mutex.Lock()
+		ejected := node.ejectedUntil.After(now)
+		node.mutex.Unlock()
+
+		if !ejected {
+			nodes = append(nodes, node)
+		}
+	}
+
+	if len(nodes) == 0 {
+		return b.nodes
+	}
+
+	return nodes
+}
+
+func (node *balancerNode) target() (server kind.CanHash, weight int) {
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+
+	return node.server, node.weight
+}
+
+// acquire 记录开始请求，返回的done记录延迟和结果
+func (b *balancer) acquire(node *balancerNode) PickDone {
+	var (
+		start = b.now()
+		once  sync.Once
+	)
+
+	node.inflight.Inc()
+	return func(err error) {
+		once.Do(func() {
+			node.inflight.Dec()
+			if err != errPickRelease {
+				b.report(node, start, err)
+			}
+		})
+	}
+}
+
+func (b *balancer) report(node *balancerNode, start time.Time, err error) {
+	now := b.now()
+
+	node.mutex.Lock()
+	defer node.mutex.Unlock()
+
+	latency := float64(now.Sub(start))
+	if node.lastDone.IsZero() || b.opts.decay <= 0 {
+		node.latency = latency
+	} else {
+		// 按距上次完成的时间衰减，间隔越久旧值权重越小
+		w := math.Exp(-float64(now.Sub(node.lastDone)) / float64(b.opts.decay))
+		node.latency = node.latency*w + latency*(1-w)
+	}
+	node.lastDone = now
+
+	if err == nil {
+		node.failures = 0
+		return
+	}
+
+	node.failures++
+	if b.opts.maxFails > 0 && node.failures >= b.opts.maxFails {
+		node.failures = 0
+		node.ejectedUntil = now.Add(b.opts.ejectTime)
+	}
+}
+
+type smoothRoundRobin struct {
+	*balancer
+}
+
+// NewSmoothRoundRobin nginx平滑加权轮询
+func NewSmoothRoundRobin(servers []kind.CanHash, opts ...BalancerOption) Picker {
+	return &smoothRoundRobin{balancer: newBalancer(servers, opts)}
+}
+
+func (srr *smoothRoundRobin) Pick() (server kind.CanHash, done PickDone, err error) {
+	// 平滑轮询需要修改current，使用写锁
+	srr.mutex.Lock()
+	var (
+		best    *balancerNode
+		total   int
+		current int
+	)
+
+	for _, node := range srr.healthy() {
+		node.mutex.Lock()
+		node.current += node.weight
+		total += node.weight
+		if best == nil || node.current > current {
+			best, current, server = node, node.current, node.server
+		}
+		node.mutex.Unlock()
+	}
+
+	if best != nil {
+		best.mutex.Lock()
+		best.current -= total
+		best.mutex.Unlock()
+	}
+	srr.mutex.Unlock()
+
+	if best == nil {
+		return nil, nil, ErrNoServer
+	}
+
+	return server, srr.acquire(best), nil
+}
+
+type p2c struct {
+	*balancer
+}
+
+// NewP2C 随机选择两个server，取EWMA延迟*(进行中请求数+1)/权重较小的一个
+func NewP2C(servers []kind.CanHash, opts ...BalancerOption) Picker {
+	return &p2c{balancer: newBalancer(servers, opts)}
+}
+
+func (p *p2c) Pick() (server kind.CanHash, done PickDone, err error) {
+	p.mutex.RLock()
+	nodes := p.healthy()
+	p.mutex.RUnlock()
+
+	var best *balancerNode
+	switch len(nodes) {
+	case 0:
+		return nil, nil, ErrNoServer
+	case 1:
+		best = nodes[0]
+	default:
+		first := rand.Intn(len(nodes))
+		second := rand.Intn(len(nodes) - 1)
+		if second >= first {
+			second++
+		}
+
+		best = nodes[first]
+		if p.load(nodes[second]) < p.load(best) {
+			best = nodes[second]
+		}
+	}
+
+	server, _ = best.target()
+	return server, p.acquire(best), nil
+}
+
+func (p *p2c) load(node *balancerNode) float64 {
+	node.mutex.Lock()
+	latency, weight := node.latency, node.weight
+	node.mutex.Unlock()
+
+	// 没有延迟数据时按1ns计算，新server优先被探测
+	return math.Max(latency, 1) * float64(node.inflight.Load()+1) / float64(weight)
+}
+
+type leastRequest struct {
+	*balancer
+}
+
+// NewLeastRequest 选择进行中请求数/权重最小的server，相同时从随机位置开始选择
+func NewLeastRequest(servers []kind.CanHash, opts ...BalancerOption) Picker {
+	return &leastRequest{balancer: newBalancer(servers, opts)}
+}
+
+func (lr *leastRequest) Pick() (server kind.CanHash, done PickDone, err error) {
+	lr.mutex.RLock()
+	nodes := lr.healthy()
+	lr.mutex.RUnlock()
+
+	if len(nodes) == 0 {
+		return nil, nil, ErrNoServer
+	}
+
+	var (
+		best   *balancerNode
+		least  float64
+		offset = rand.Intn(len(nodes))
+	)
+
+	for index := range nodes {
+		node := nodes[(offset+index)%len(nodes)]
+		target, weight := node.target()
+		load := float64(node.inflight.Load()) / float64(weight)
+		if best == nil || load < least {
+			best, least, server = node, load, target
+		}
+	}
+
+	return server, lr.acquire(best), nil
+}
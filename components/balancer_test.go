@@ -0,0 +1,200 @@
+package components
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grpc-boot/base/v3/kind"
+	"github.com/grpc-boot/base/v3/utils"
+)
+
+type weightedServer struct {
+	id     string
+	weight int
+}
+
+func (ws *weightedServer) HashCode() uint32 {
+	return kind.Uint32Hash(utils.String2Bytes(ws.id))
+}
+
+func (ws *weightedServer) Weight() int {
+	return ws.weight
+}
+
+func servers(weights ...int) []kind.CanHash {
+	list := make([]kind.CanHash, len(weights))
+	for index, weight := range weights {
+		list[index] = &weightedServer{id: string(rune('a' + index)), weight: weight}
+	}
+	return list
+}
+
+func TestSmoothRoundRobin(t *testing.T) {
+	picker := NewSmoothRoundRobin(servers(5, 1, 1))
+
+	var sequence []string
+	for i := 0; i < 7; i++ {
+		server, done, err := picker.Pick()
+		if err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+		done(nil)
+		sequence = append(sequence, server.(*weightedServer).id)
+	}
+
+	if got := strings.Join(sequence, ""); got != "aabacaa" {
+		t.Fatalf("want aabacaa, got %s", got)
+	}
+
+	if _, _, err := NewSmoothRoundRobin(nil).Pick(); err != ErrNoServer {
+		t.Fatalf("want ErrNoServer, got %v", err)
+	}
+}
+
+func TestBalancer_Eject(t *testing.T) {
+	var (
+		clock  = &fakeClock{now: time.Unix(1700000000, 0)}
+		picker = NewSmoothRoundRobin(servers(1, 1), WithMaxFails(2), WithEjectTime(time.Second))
+		failed = errors.New("failed")
+	)
+	picker.(*smoothRoundRobin).now = clock.Now
+
+	// a连续失败两次后被摘除
+	for i := 0; i < 4; i++ {
+		server, done, _ := picker.Pick()
+		if server.(*weightedServer).id == "a" {
+			done(failed)
+		} else {
+			done(nil)
+		}
+	}
+
+	for i := 0; i < 4; i++ {
+		server, done, _ := picker.Pick()
+		done(nil)
+		if server.(*weightedServer).id != "b" {
+			t.Fatalf("want b, got %s", server.(*weightedServer).id)
+		}
+	}
+
+	if stats := picker.Stats(); !stats[0].Ejected || stats[1].Ejected {
+		t.Fatalf("want a ejected, got %+v", stats)
+	}
+
+	clock.Add(time.Second)
+	if stats := picker.Stats(); stats[0].Ejected {
+		t.Fatalf("want a recovered, got %+v", stats)
+	}
+
+	// 全部被摘除时仍然可以选择
+	picker.Store(servers(1)...)
+	for i := 0; i < 2; i++ {
+		_, done, _ := picker.Pick()
+		done(failed)
+	}
+
+	if _, _, err := picker.Pick(); err != nil || picker.Length() != 1 {
+		t.Fatalf("want fallback pick, got %v", err)
+	}
+}
+
+func TestP2C(t *testing.T) {
+	var (
+		clock  = &fakeClock{now: time.Unix(1700000000, 0)}
+		picker = NewP2C(servers(1, 1))
+		b      = picker.(*p2c).balancer
+	)
+	b.now = clock.Now
+
+	// a延迟10ms，b延迟100ms
+	for _, node := range b.nodes {
+		done := b.acquire(node)
+		if node.server.(*weightedServer).id == "a" {
+			clock.Add(time.Millisecond * 10)
+		} else {
+			clock.Add(time.Millisecond * 100)
+		}
+		done(nil)
+	}
+
+	for i := 0; i < 10; i++ {
+		server, done, _ := picker.Pick()
+		done(nil)
+		if server.(*weightedServer).id != "a" {
+			t.Fatalf("want a, got %s", server.(*weightedServer).id)
+		}
+	}
+
+	if stats := picker.Stats(); stats[1].Latency != time.Millisecond*100 {
+		t.Fatalf("want 100ms, got %v", stats[1].Latency)
+	}
+}
+
+func TestLeastRequest(t *testing.T) {
+	picker := NewLeastRequest(servers(1, 2, 1))
+
+	var dones []func(error)
+	counts := map[string]int{}
+	for i := 0; i < 8; i++ {
+		server, done, _ := picker.Pick()
+		dones = append(dones, done)
+		counts[server.(*weightedServer).id]++
+	}
+
+	if counts["a"] != 2 || counts["b"] != 4 || counts["c"] != 2 {
+		t.Fatalf("want 2 4 2, got %v", counts)
+	}
+
+	for _, done := range dones {
+		done(nil)
+		done(nil)
+	}
+
+	for _, stat := range picker.Stats() {
+		if stat.Inflight != 0 {
+			t.Fatalf("want 0, got %d", stat.Inflight)
+		}
+	}
+}
+
+func TestPickDone_Release(t *testing.T) {
+	picker := NewSmoothRoundRobin(servers(1), WithMaxFails(1))
+
+	// 释放的请求不计为成功也不计为失败
+	_, done, _ := picker.Pick()
+	done.Release()
+	done(errors.New("failed"))
+
+	if stat := picker.Stats()[0]; stat.Inflight != 0 || stat.Failures != 0 || stat.Ejected || stat.Latency != 0 {
+		t.Fatalf("want untouched stat, got %+v", stat)
+	}
+}
+
+func TestBalancer_StoreRace(t *testing.T) {
+	// Store替换权重时与Pick并发，需要使用-race运行
+	for _, picker := range []Picker{NewSmoothRoundRobin(servers(1, 2)), NewP2C(servers(1, 2)), NewLeastRequest(servers(1, 2))} {
+		var wg sync.WaitGroup
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				picker.Store(servers(i%3+1, 2)...)
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				if _, done, err := picker.Pick(); err == nil {
+					done(nil)
+				}
+			}
+		}()
+
+		wg.Wait()
+	}
+}
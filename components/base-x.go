@@ -0,0 +1,237 @@
+package components
+
+import (
+	"math"
+)
+
+const (
+	Base62Charset    = `0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz`
+	Base58Charset    = `123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz`
+	CrockfordCharset = `0123456789ABCDEFGHJKMNPQRSTVWXYZ`
+)
+
+var (
+	Base62Encoding, _ = NewBaseX([]byte(Base62Charset))
+	Base58Encoding, _ = NewBaseX([]byte(Base58Charset))
+	CrockfordEncoding = newCrockford()
+)
+
+// Encoder 字节编码
+type Encoder interface {
+	Encode(src []byte) []byte
+}
+
+// Decoder 字节解码
+type Decoder interface {
+	Decode(src []byte) ([]byte, error)
+}
+
+// Encoding 字节编解码
+type Encoding interface {
+	Encoder
+	Decoder
+}
+
+// baseX 任意长度字节的大数进制转换，前导0字节编码为字符集第一个字符
+type baseX struct {
+	charset   []byte
+	decodeMap [256]int16
+	// 编码、解码时每字节/字符所需长度的千分比，用于预估结果长度
+	encodeFactor int
+	decodeFactor int
+}
+
+// NewBaseX 使用字符集实例化大数进制编码，字符集长度[2, 256]且不能重复
+func NewBaseX(charset []byte) (Encoding, error) {
+	if len(charset) < 2 || len(charset) > 256 {
+		return nil, ErrCharset
+	}
+
+	b := &baseX{
+		charset: append([]byte{}, charset...),
+	}
+
+	for i := range b.decodeMap {
+		b.decodeMap[i] = -1
+	}
+
+	for i, c := range charset {
+		if b.decodeMap[c] >= 0 {
+			return nil, ErrCharset
+		}
+		b.decodeMap[c] = int16(i)
+	}
+
+	ratio := math.Log(256) / math.Log(float64(len(charset)))
+	b.encodeFactor = int(math.Ceil(ratio * 1000))
+	b.decodeFactor = int(math.Ceil(1000 / ratio))
+	return b, nil
+}
+
+func (b *baseX) Encode(src []byte) []byte {
+	zeros := 0
+	for zeros < len(src) && src[zeros] == 0 {
+		zeros++
+	}
+
+	var (
+		base = len(b.charset)
+		size = (len(src)-zeros)*b.encodeFactor/1000 + 1
+		buf  = make([]byte, size)
+		high = size - 1
+	)
+
+	for _, c := range src[zeros:] {
+		carry := int(c)
+		j := size - 1
+		for ; j > high || carry != 0; j-- {
+			carry += int(buf[j]) << 8
+			buf[j] = byte(carry % base)
+			carry /= base
+		}
+		high = j
+	}
+
+	start := 0
+	for start < size && buf[start] == 0 {
+		start++
+	}
+
+	out := make([]byte, zeros+size-start)
+	for i := 0; i < zeros; i++ {
+		out[i] = b.charset[0]
+	}
+
+	for i, digit := range buf[start:] {
+		out[zeros+i] = b.charset[digit]
+	}
+
+	return out
+}
+
+func (b *baseX) Decode(src []byte) ([]byte, error) {
+	zeros := 0
+	for zeros < len(src) && src[zeros] == b.charset[0] {
+		zeros++
+	}
+
+	var (
+		base = len(b.charset)
+		size = (len(src)-zeros)*b.decodeFactor/1000 + 1
+		buf  = make([]byte, size)
+		high = size - 1
+	)
+
+	for _, c := range src[zeros:] {
+		value := b.decodeMap[c]
+		if value < 0 {
+			return nil, ErrDataFormat
+		}
+
+		carry := int(value)
+		j := size - 1
+		for ; j > high || carry != 0; j-- {
+			carry += int(buf[j]) * base
+			buf[j] = byte(carry)
+			carry >>= 8
+		}
+		high = j
+	}
+
+	start := 0
+	for start < size && buf[start] == 0 {
+		start++
+	}
+
+	out := make([]byte, zeros+size-start)
+	copy(out[zeros:], buf[start:])
+	return out, nil
+}
+
+// crockford Crockford Base32，按5位分组编码不填充，解码时忽略大小写和'-'，O映射为0，I、L映射为1
+type crockford struct {
+	decodeMap [256]int8
+}
+
+func newCrockford() Encoding {
+	c := &crockford{}
+	for i := range c.decodeMap {
+		c.decodeMap[i] = -1
+	}
+
+	for i := 0; i < len(CrockfordCharset); i++ {
+		upper := CrockfordCharset[i]
+		c.decodeMap[upper] = int8(i)
+		if upper >= 'A' && upper <= 'Z' {
+			c.decodeMap[upper+'a'-'A'] = int8(i)
+		}
+	}
+
+	for _, ch := range []byte("Oo") {
+		c.decodeMap[ch] = 0
+	}
+
+	for _, ch := range []byte("IiLl") {
+		c.decodeMap[ch] = 1
+	}
+
+	return c
+}
+
+func (c *crockford) Encode(src []byte) []byte {
+	var (
+		out   = make([]byte, 0, (len(src)*8+4)/5)
+		acc   uint
+		nbits uint
+	)
+
+	for _, b := range src {
+		acc = acc<<8 | uint(b)
+		nbits += 8
+		for nbits >= 5 {
+			nbits -= 5
+			out = append(out, CrockfordCharset[(acc>>nbits)&31])
+		}
+		acc &= 1<<nbits - 1
+	}
+
+	if nbits > 0 {
+		out = append(out, CrockfordCharset[(acc<<(5-nbits))&31])
+	}
+
+	return out
+}
+
+func (c *crockford) Decode(src []byte) ([]byte, error) {
+	var (
+		out   = make([]byte, 0, len(src)*5/8)
+		acc   uint
+		nbits uint
+	)
+
+	for _, ch := range src {
+		if ch == '-' {
+			continue
+		}
+
+		value := c.decodeMap[ch]
+		if value < 0 {
+			return nil, ErrDataFormat
+		}
+
+		acc = acc<<5 | uint(value)
+		nbits += 5
+		if nbits >= 8 {
+			nbits -= 8
+			out = append(out, byte(acc>>nbits))
+			acc &= 1<<nbits - 1
+		}
+	}
+
+	// 剩余位只能是编码时补齐的0
+	if nbits >= 5 || acc != 0 {
+		return nil, ErrDataFormat
+	}
+
+	return out, nil
+}
@@ -0,0 +1,89 @@
+package components
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestBaseXVectors(t *testing.T) {
+	cases := []struct {
+		encoding Encoding
+		src      []byte
+		want     string
+	}{
+		{Base58Encoding, []byte("Hello World!"), "2NEpo7TZRRrLZSi2U"},
+		{Base58Encoding, []byte{0, 0, 0x28, 0x7f, 0xb4, 0xcd}, "11233QC4"},
+		{Base62Encoding, []byte("Hello World!"), "T8dgcjRGkZ3aysdN"},
+		{Base62Encoding, []byte{0, 0}, "00"},
+		{Base62Encoding, []byte{}, ""},
+		{CrockfordEncoding, []byte("foobar"), "CSQPYRK1E8"},
+		{CrockfordEncoding, []byte("f"), "CR"},
+	}
+
+	for _, c := range cases {
+		got := string(c.encoding.Encode(c.src))
+		if got != c.want {
+			t.Fatalf("want %s, got %s", c.want, got)
+		}
+
+		data, err := c.encoding.Decode([]byte(c.want))
+		if err != nil || !bytes.Equal(data, c.src) {
+			t.Fatalf("want %x, got %x %v", c.src, data, err)
+		}
+	}
+}
+
+func TestBaseXRandom(t *testing.T) {
+	binary, _ := NewBaseX([]byte("01"))
+	for _, encoding := range []Encoding{Base62Encoding, Base58Encoding, CrockfordEncoding, binary} {
+		for size := 0; size < 70; size++ {
+			src := make([]byte, size)
+			_, _ = rand.Read(src)
+			if size%3 == 0 && size > 0 {
+				src[0] = 0
+			}
+
+			data, err := encoding.Decode(encoding.Encode(src))
+			if err != nil || !bytes.Equal(data, src) {
+				t.Fatalf("want %x, got %x %v", src, data, err)
+			}
+		}
+	}
+}
+
+func TestCrockfordDecode(t *testing.T) {
+	data, err := CrockfordEncoding.Decode([]byte("csqp-yrkl-e8"))
+	if err != nil || string(data) != "foobar" {
+		t.Fatalf("want foobar, got %s %v", data, err)
+	}
+
+	zero, _ := CrockfordEncoding.Decode([]byte("0000"))
+	o, err := CrockfordEncoding.Decode([]byte("oOOo"))
+	if err != nil || !bytes.Equal(zero, o) {
+		t.Fatalf("want %x, got %x %v", zero, o, err)
+	}
+
+	if _, err = CrockfordEncoding.Decode([]byte("CU")); err != ErrDataFormat {
+		t.Fatalf("want ErrDataFormat, got %v", err)
+	}
+
+	// 补齐位不为0
+	if _, err = CrockfordEncoding.Decode([]byte("CS")); err != ErrDataFormat {
+		t.Fatalf("want ErrDataFormat, got %v", err)
+	}
+}
+
+func TestNewBaseX(t *testing.T) {
+	if _, err := NewBaseX([]byte("a")); err != ErrCharset {
+		t.Fatalf("want ErrCharset, got %v", err)
+	}
+
+	if _, err := NewBaseX([]byte("abca")); err != ErrCharset {
+		t.Fatalf("want ErrCharset, got %v", err)
+	}
+
+	if _, err := Base58Encoding.Decode([]byte("0OIl")); err != ErrDataFormat {
+		t.Fatalf("want ErrDataFormat, got %v", err)
+	}
+}
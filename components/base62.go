@@ -14,6 +14,20 @@ type Base62 interface {
 	Decode(data []byte) (uint64, error)
 }
 
+// Base62Sizer 可以获取字符集长度的Base62，NewBase62返回的实例实现了该接口
+type Base62Sizer interface {
+	Size() int
+}
+
+// base62Size 字符集长度，未实现Base62Sizer时为62
+func base62Size(b Base62) int {
+	if sizer, ok := b.(Base62Sizer); ok {
+		return sizer.Size()
+	}
+
+	return 62
+}
+
 type base62 struct {
 	charset []byte
 	charmap [62]int
@@ -56,6 +70,11 @@ func (b *base62) charIndex(c int) int {
 	return 63
 }
 
+// Size 字符集长度
+func (b *base62) Size() int {
+	return len(b.charset)
+}
+
 func (b *base62) Encode(value uint64, length int) []byte {
 	if value == 0 {
 		return bytes.Repeat([]byte{b.charset[0]}, length)
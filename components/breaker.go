@@ -0,0 +1,384 @@
+package components
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+const (
+	EventBreakerStateChange = `breaker.state.change`
+)
+
+var (
+	ErrBreakerOpen          = errors.New("circuit breaker is open")
+	ErrBreakerProbeExceeded = errors.New("circuit breaker probe budget exceeded")
+)
+
+var (
+	errBreakerRelease = errors.New("circuit breaker release")
+	errBreakerPanic   = errors.New("circuit breaker panic")
+)
+
+// BreakerState 熔断器状态
+type BreakerState uint8
+
+func (bs BreakerState) String() string {
+	switch bs {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+// BreakerStateChange 状态变更事件数据
+type BreakerStateChange struct {
+	Name string
+	From BreakerState
+	To   BreakerState
+}
+
+// BreakerDone 使用执行结果调用，只有第一次调用生效
+type BreakerDone func(err error)
+
+// Release 释放执行许可但不记录结果，用于调用方取消等不能反映下游状态的情况，半开状态下归还探测名额
+func (bd BreakerDone) Release() {
+	bd(errBreakerRelease)
+}
+
+// BreakerOption 熔断器选项
+type BreakerOption func(b *Breaker)
+
+// WithBreakerWindow 统计失败率的滑动窗口，分为buckets个桶，默认10秒10个桶，buckets<1时为1
+func WithBreakerWindow(window time.Duration, buckets int) BreakerOption {
+	return func(b *Breaker) {
+		if buckets < 1 {
+			buckets = 1
+		}
+
+		b.window = window
+		b.buckets = make([]breakerBucket, buckets)
+	}
+}
+
+// WithFailureRate 窗口内失败率达到rate时熔断，默认0.5
+func WithFailureRate(rate float64) BreakerOption {
+	return func(b *Breaker) {
+		b.failureRate = rate
+	}
+}
+
+// WithMinRequests 窗口内请求数达到n时才计算失败率，默认20
+func WithMinRequests(n int64) BreakerOption {
+	return func(b *Breaker) {
+		b.minRequests = n
+	}
+}
+
+// WithOpenTimeout 熔断后经过timeout进入半开状态，默认30秒
+func WithOpenTimeout(timeout time.Duration) BreakerOption {
+	return func(b *Breaker) {
+		b.openTimeout = timeout
+	}
+}
+
+// WithProbeBudget 半开状态允许的探测请求数，全部成功后关闭，任意失败重新熔断，默认1，n<1时按1处理
+func WithProbeBudget(n int) BreakerOption {
+	return func(b *Breaker) {
+		if n < 1 {
+			n = 1
+		}
+
+		b.probeBudget = n
+	}
+}
+
+// WithBreakerEvents 状态变更时触发EventBreakerStateChange，事件数据为*BreakerStateChange
+func WithBreakerEvents(em *EventManager) BreakerOption {
+	return func(b *Breaker) {
+		b.events = em
+	}
+}
+
+// WithIsFailure 判断err是否计为失败，默认err != nil，例如可以忽略参数错误
+func WithIsFailure(isFailure func(err error) bool) BreakerOption {
+	return func(b *Breaker) {
+		b.isFailure = isFailure
+	}
+}
+
+type breakerBucket struct {
+	slot    int64
+	success int64
+	failure int64
+}
+
+// Breaker 熔断器，关闭状态下统计滑动窗口内的失败率，达到阈值后熔断，超时后进入半开状态放行探测请求
+type Breaker struct {
+	name        string
+	mutex       sync.Mutex
+	state       BreakerState
+	generation  uint64
+	window      time.Duration
+	buckets     []breakerBucket
+	failureRate float64
+	minRequests int64
+	openTimeout time.Duration
+	probeBudget int
+	probes      int
+	probeOk     int
+	openedAt    time.Time
+	events      *EventManager
+	isFailure   func(err error) bool
+	now         func() time.Time
+}
+
+// NewBreaker 实例化熔断器，name用于事件数据
+func NewBreaker(name string, opts ...BreakerOption) *Breaker {
+	b := &Breaker{
+		name:        name,
+		window:      time.Second * 10,
+		buckets:     make([]breakerBucket, 10),
+		failureRate: 0.5,
+		minRequests: 20,
+		openTimeout: time.Second * 30,
+		probeBudget: 1,
+		isFailure: func(err error) bool {
+			return err != nil
+		},
+		now: time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Name 名称
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State 当前状态，打开状态超时后返回BreakerHalfOpen
+func (b *Breaker) State() BreakerState {
+	b.mutex.Lock()
+	change := b.refresh(b.now())
+	state := b.state
+	b.mutex.Unlock()
+
+	b.notify(change)
+	return state
+}
+
+// Counts 窗口内的成功和失败次数
+func (b *Breaker) Counts() (success, failure int64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.counts(b.now())
+}
+
+// Allow 申请执行，允许时返回done，调用方必须使用执行结果调用done或调用done.Release
+func (b *Breaker) Allow() (done BreakerDone, err error) {
+	b.mutex.Lock()
+	now := b.now()
+	change := b.refresh(now)
+
+	switch b.state {
+	case BreakerOpen:
+		err = ErrBreakerOpen
+	case BreakerHalfOpen:
+		if b.probes >= b.probeBudget {
+			err = ErrBreakerProbeExceeded
+		} else {
+			b.probes++
+		}
+	}
+
+	generation := b.generation
+	b.mutex.Unlock()
+
+	b.notify(change)
+	if err != nil {
+		return nil, err
+	}
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			switch err {
+			case errBreakerRelease:
+				b.release(generation)
+			case errBreakerPanic:
+				b.done(generation, true)
+			default:
+				b.done(generation, b.isFailure(err))
+			}
+		})
+	}, nil
+}
+
+// Execute 在熔断器保护下执行fn，fn发生panic时计为失败并继续panic
+func (b *Breaker) Execute(fn func() error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+
+	defer done(errBreakerPanic)
+
+	err = fn()
+	done(err)
+	return err
+}
+
+// BreakerDo 在熔断器保护下执行有返回值的fn，fn发生panic时计为失败并继续panic
+func BreakerDo[T any](b *Breaker, fn func() (T, error)) (value T, err error) {
+	done, err := b.Allow()
+	if err != nil {
+		return value, err
+	}
+
+	defer done(errBreakerPanic)
+
+	value, err = fn()
+	done(err)
+	return value, err
+}
+
+func (b *Breaker) done(generation uint64, failure bool) {
+	b.mutex.Lock()
+
+	// 状态已经变更，丢弃之前状态下发出的请求结果
+	if generation != b.generation {
+		b.mutex.Unlock()
+		return
+	}
+
+	var (
+		now    = b.now()
+		change *BreakerStateChange
+	)
+
+	switch b.state {
+	case BreakerClosed:
+		bucket := b.bucket(now)
+		if failure {
+			bucket.failure++
+		} else {
+			bucket.success++
+		}
+
+		if failure && b.tripped(now) {
+			change = b.setState(BreakerOpen, now)
+		}
+	case BreakerHalfOpen:
+		if failure {
+			change = b.setState(BreakerOpen, now)
+		} else if b.probeOk++; b.probeOk >= b.probeBudget {
+			change = b.setState(BreakerClosed, now)
+		}
+	}
+
+	b.mutex.Unlock()
+	b.notify(change)
+}
+
+func (b *Breaker) release(generation uint64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if generation == b.generation && b.state == BreakerHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+func (b *Breaker) counts(now time.Time) (success, failure int64) {
+	minSlot := b.slot(now) - int64(len(b.buckets)) + 1
+	for _, bucket := range b.buckets {
+		if bucket.slot >= minSlot {
+			success += bucket.success
+			failure += bucket.failure
+		}
+	}
+
+	return
+}
+
+func (b *Breaker) tripped(now time.Time) bool {
+	success, failure := b.counts(now)
+	total := success + failure
+	return total >= b.minRequests && total > 0 && float64(failure)/float64(total) >= b.failureRate
+}
+
+func (b *Breaker) slot(now time.Time) int64 {
+	size := b.window / time.Duration(len(b.buckets))
+	if size <= 0 {
+		size = 1
+	}
+
+	return now.UnixNano() / int64(size)
+}
+
+func (b *Breaker) bucket(now time.Time) *breakerBucket {
+	slot := b.slot(now)
+	bucket := &b.buckets[slot%int64(len(b.buckets))]
+	if bucket.slot != slot {
+		*bucket = breakerBucket{slot: slot}
+	}
+
+	return bucket
+}
+
+// refresh 打开状态超时后进入半开状态
+func (b *Breaker) refresh(now time.Time) *BreakerStateChange {
+	if b.state == BreakerOpen && now.Sub(b.openedAt) >= b.openTimeout {
+		return b.setState(BreakerHalfOpen, now)
+	}
+
+	return nil
+}
+
+func (b *Breaker) setState(state BreakerState, now time.Time) *BreakerStateChange {
+	change := &BreakerStateChange{
+		Name: b.name,
+		From: b.state,
+		To:   state,
+	}
+
+	b.state = state
+	b.generation++
+	b.probes, b.probeOk = 0, 0
+
+	switch state {
+	case BreakerOpen:
+		b.openedAt = now
+	case BreakerClosed:
+		for i := range b.buckets {
+			b.buckets[i] = breakerBucket{}
+		}
+	}
+
+	return change
+}
+
+func (b *Breaker) notify(change *BreakerStateChange) {
+	if change == nil || b.events == nil {
+		return
+	}
+
+	b.events.Trigger(EventBreakerStateChange, change)
+}
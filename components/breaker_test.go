@@ -0,0 +1,200 @@
+package components
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errBreakerTest = errors.New("breaker test")
+
+func TestBreaker(t *testing.T) {
+	var (
+		em      EventManager
+		changes []BreakerStateChange
+		clock   = &fakeClock{now: time.Unix(1700000000, 0)}
+	)
+
+	em.On(EventBreakerStateChange, func(ctx *Context) {
+		changes = append(changes, *ctx.Event().Data().(*BreakerStateChange))
+	})
+
+	b := NewBreaker("user-service",
+		WithMinRequests(4),
+		WithFailureRate(0.5),
+		WithOpenTimeout(time.Second*5),
+		WithProbeBudget(2),
+		WithBreakerEvents(&em),
+	)
+	b.now = clock.Now
+
+	// 请求量不足时不熔断
+	for i := 0; i < 3; i++ {
+		_ = b.Execute(func() error { return errBreakerTest })
+	}
+
+	if b.State() != BreakerClosed {
+		t.Fatalf("want closed, got %s", b.State())
+	}
+
+	_ = b.Execute(func() error { return nil })
+	_ = b.Execute(func() error { return errBreakerTest })
+	if b.State() != BreakerOpen {
+		t.Fatalf("want open, got %s", b.State())
+	}
+
+	if err := b.Execute(func() error { return nil }); err != ErrBreakerOpen {
+		t.Fatalf("want ErrBreakerOpen, got %v", err)
+	}
+
+	// 超时后半开，探测预算为2
+	clock.Add(time.Second * 5)
+	done1, err := b.Allow()
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	done2, _ := b.Allow()
+	if _, err = b.Allow(); err != ErrBreakerProbeExceeded {
+		t.Fatalf("want ErrBreakerProbeExceeded, got %v", err)
+	}
+
+	done1(nil)
+	done2(errBreakerTest)
+	if b.State() != BreakerOpen {
+		t.Fatalf("want open, got %s", b.State())
+	}
+
+	clock.Add(time.Second * 5)
+	for i := 0; i < 2; i++ {
+		value, err := BreakerDo(b, func() (int, error) { return i, nil })
+		if err != nil || value != i {
+			t.Fatalf("want %d, got %d %v", i, value, err)
+		}
+	}
+
+	if b.State() != BreakerClosed {
+		t.Fatalf("want closed, got %s", b.State())
+	}
+
+	if success, failure := b.Counts(); success != 0 || failure != 0 {
+		t.Fatalf("want reset counts, got %d %d", success, failure)
+	}
+
+	want := []BreakerState{BreakerClosed, BreakerOpen, BreakerOpen, BreakerHalfOpen, BreakerHalfOpen, BreakerOpen, BreakerOpen, BreakerHalfOpen, BreakerHalfOpen, BreakerClosed}
+	if len(changes) != len(want)/2 {
+		t.Fatalf("want %d changes, got %d", len(want)/2, len(changes))
+	}
+
+	for i, change := range changes {
+		if change.Name != "user-service" || change.From != want[2*i] || change.To != want[2*i+1] {
+			t.Fatalf("want %s->%s, got %s->%s", want[2*i], want[2*i+1], change.From, change.To)
+		}
+	}
+}
+
+func TestBreakerWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := NewBreaker("window", WithBreakerWindow(time.Second, 10), WithMinRequests(2), WithIsFailure(func(err error) bool {
+		return err != nil && err != ErrDataFormat
+	}))
+	b.now = clock.Now
+
+	_ = b.Execute(func() error { return errBreakerTest })
+
+	// 移出窗口的失败不再计入
+	clock.Add(time.Second)
+	_ = b.Execute(func() error { return ErrDataFormat })
+	_ = b.Execute(func() error { return errBreakerTest })
+
+	if success, failure := b.Counts(); success != 1 || failure != 1 {
+		t.Fatalf("want 1 1, got %d %d", success, failure)
+	}
+
+	if b.State() != BreakerOpen {
+		t.Fatalf("want open, got %s", b.State())
+	}
+
+	// 桶数不合法时使用1个桶
+	b = NewBreaker("window", WithBreakerWindow(time.Second, 0))
+	if err := b.Execute(func() error { return nil }); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if success, _ := b.Counts(); success != 1 {
+		t.Fatalf("want 1, got %d", success)
+	}
+}
+
+func TestBreakerRelease(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := NewBreaker("release", WithMinRequests(1), WithOpenTimeout(time.Second))
+	b.now = clock.Now
+
+	_ = b.Execute(func() error { return errBreakerTest })
+	clock.Add(time.Second)
+
+	// 释放的探测不计为成功，名额归还
+	done, err := b.Allow()
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	done.Release()
+	done(nil)
+	if b.State() != BreakerHalfOpen {
+		t.Fatalf("want half-open, got %s", b.State())
+	}
+
+	if done, err = b.Allow(); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	done(nil)
+	if b.State() != BreakerClosed {
+		t.Fatalf("want closed, got %s", b.State())
+	}
+}
+
+func TestBreakerPanic(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := NewBreaker("panic", WithMinRequests(1), WithOpenTimeout(time.Second))
+	b.now = clock.Now
+
+	_ = b.Execute(func() error { return errBreakerTest })
+	clock.Add(time.Second)
+
+	// 半开状态下探测panic计为失败，重新熔断而不是一直占用探测名额
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("want panic")
+			}
+		}()
+
+		_, _ = BreakerDo(b, func() (int, error) { panic("boom") })
+	}()
+
+	if b.State() != BreakerOpen {
+		t.Fatalf("want open, got %s", b.State())
+	}
+
+	clock.Add(time.Second)
+	if err := b.Execute(func() error { return nil }); err != nil || b.State() != BreakerClosed {
+		t.Fatalf("want closed, got %s %v", b.State(), err)
+	}
+}
+
+func TestBreakerProbeBudget(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := NewBreaker("probe", WithMinRequests(1), WithOpenTimeout(time.Second), WithProbeBudget(0))
+	b.now = clock.Now
+
+	_ = b.Execute(func() error { return errBreakerTest })
+	clock.Add(time.Second)
+
+	// 探测预算不合法时按1处理，半开后仍能恢复
+	if err := b.Execute(func() error { return nil }); err != nil || b.State() != BreakerClosed {
+		t.Fatalf("want closed, got %s %v", b.State(), err)
+	}
+}
@@ -0,0 +1,365 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grpc-boot/base/v3/gopool"
+	"github.com/grpc-boot/base/v3/logger"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrCronStopped    = errors.New("cron scheduler stopped")
+	ErrCronJobMissing = errors.New("cron job not found")
+)
+
+// CronEntry 任务快照，用于查看下次执行时间
+type CronEntry struct {
+	Id      int64
+	Name    string
+	Spec    string
+	Prev    time.Time
+	Next    time.Time
+	Running bool
+}
+
+type cronJob struct {
+	id       int64
+	name     string
+	spec     string
+	schedule CronSchedule
+	fn       func(ctx context.Context)
+	prev     time.Time
+	next     time.Time
+	fireAt   time.Time
+	running  atomic.Bool
+}
+
+// CronOption 调度器选项
+type CronOption func(c *Cron)
+
+// WithCronLocation 计算执行时间使用的时区，默认time.Local，表达式中的TZ=优先
+func WithCronLocation(location *time.Location) CronOption {
+	return func(c *Cron) {
+		c.location = location
+	}
+}
+
+// WithCronJitter 每次执行随机延迟[0, jitter)，避免多个实例同时执行
+func WithCronJitter(jitter time.Duration) CronOption {
+	return func(c *Cron) {
+		c.jitter = jitter
+	}
+}
+
+// Cron 定时任务调度器，任务在gopool.Pool中执行，同一任务上一次未结束时跳过本次执行
+type Cron struct {
+	mutex    sync.Mutex
+	pool     *gopool.Pool
+	jobs     map[int64]*cronJob
+	nextId   int64
+	location *time.Location
+	jitter   time.Duration
+	wake     chan struct{}
+	done     chan struct{}
+	stopped  chan struct{}
+	running  sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+	started  bool
+	closed   bool
+	now      func() time.Time
+}
+
+// NewCron 实例化调度器
+func NewCron(pool *gopool.Pool, opts ...CronOption) *Cron {
+	c := &Cron{
+		pool:     pool,
+		jobs:     make(map[int64]*cronJob),
+		location: time.Local,
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	return c
+}
+
+// Add 添加任务，fn的ctx在Stop等待超时后取消
+func (c *Cron) Add(name, spec string, fn func(ctx context.Context)) (id int64, err error) {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.Schedule(name, spec, schedule, fn)
+}
+
+// Schedule 使用自定义的CronSchedule添加任务
+func (c *Cron) Schedule(name, spec string, schedule CronSchedule, fn func(ctx context.Context)) (id int64, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return 0, ErrCronStopped
+	}
+
+	c.nextId++
+	job := &cronJob{
+		id:       c.nextId,
+		name:     name,
+		spec:     spec,
+		schedule: schedule,
+		fn:       fn,
+	}
+
+	c.plan(job, c.now().In(c.location))
+	c.jobs[job.id] = job
+	c.notify()
+
+	return job.id, nil
+}
+
+// Remove 移除任务，正在执行的不受影响
+func (c *Cron) Remove(id int64) {
+	c.mutex.Lock()
+	delete(c.jobs, id)
+	c.mutex.Unlock()
+
+	c.notify()
+}
+
+// Entry 获取任务快照
+func (c *Cron) Entry(id int64) (entry CronEntry, err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	job, exists := c.jobs[id]
+	if !exists {
+		return entry, ErrCronJobMissing
+	}
+
+	return job.entry(), nil
+}
+
+// Entries 所有任务快照，按下次执行时间排序
+func (c *Cron) Entries() []CronEntry {
+	c.mutex.Lock()
+	entries := make([]CronEntry, 0, len(c.jobs))
+	for _, job := range c.jobs {
+		entries = append(entries, job.entry())
+	}
+	c.mutex.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Next.IsZero() {
+			return false
+		}
+
+		return entries[j].Next.IsZero() || entries[i].Next.Before(entries[j].Next)
+	})
+
+	return entries
+}
+
+// Start 启动调度，重复调用无效
+func (c *Cron) Start() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.started || c.closed {
+		return
+	}
+
+	c.started = true
+	go c.loop()
+}
+
+// Stop 停止调度并等待正在执行的任务结束，可直接用于grace.Graceful.OnShutdown
+func (c *Cron) Stop() error {
+	return c.StopWithContext(context.Background())
+}
+
+// StopWithContext 停止调度并等待正在执行的任务结束，ctx结束时取消任务的ctx并返回ctx.Err()
+func (c *Cron) StopWithContext(ctx context.Context) error {
+	c.mutex.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.done)
+		if !c.started {
+			close(c.stopped)
+		}
+	}
+	c.mutex.Unlock()
+
+	// 调度循环可能阻塞在向已满的pool提交任务
+	select {
+	case <-c.stopped:
+	case <-ctx.Done():
+		c.cancel()
+		return ctx.Err()
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		c.running.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		c.cancel()
+		return nil
+	case <-ctx.Done():
+		c.cancel()
+		return ctx.Err()
+	}
+}
+
+func (c *Cron) loop() {
+	defer close(c.stopped)
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		c.mutex.Lock()
+		fireAt := c.earliest()
+		c.mutex.Unlock()
+
+		delay := time.Hour
+		if !fireAt.IsZero() {
+			delay = fireAt.Sub(c.now())
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(delay)
+
+		select {
+		case <-timer.C:
+			c.runDue()
+		case <-c.wake:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *Cron) earliest() (fireAt time.Time) {
+	for _, job := range c.jobs {
+		if job.fireAt.IsZero() {
+			continue
+		}
+
+		if fireAt.IsZero() || job.fireAt.Before(fireAt) {
+			fireAt = job.fireAt
+		}
+	}
+
+	return
+}
+
+func (c *Cron) runDue() {
+	c.mutex.Lock()
+	var (
+		now = c.now().In(c.location)
+		due []*cronJob
+	)
+
+	for _, job := range c.jobs {
+		if job.fireAt.IsZero() || job.fireAt.After(now) {
+			continue
+		}
+
+		job.prev = job.next
+		c.plan(job, now)
+		due = append(due, job)
+	}
+	c.mutex.Unlock()
+
+	// pool已满时Submit会阻塞，不能持有锁，否则任务中调用Entries等方法会死锁
+	for _, job := range due {
+		select {
+		case <-c.done:
+			return
+		default:
+		}
+
+		c.dispatch(job)
+	}
+}
+
+func (c *Cron) plan(job *cronJob, now time.Time) {
+	job.next = job.schedule.Next(now)
+	job.fireAt = job.next
+	if !job.next.IsZero() && c.jitter > 0 {
+		job.fireAt = job.next.Add(time.Duration(rand.Int63n(int64(c.jitter))))
+	}
+}
+
+func (c *Cron) dispatch(job *cronJob) {
+	// 上一次执行未结束时跳过
+	if !job.running.CompareAndSwap(false, true) {
+		logger.Warn("cron job skipped, previous run still in progress",
+			zap.Int64("Id", job.id),
+			zap.String("Name", job.name),
+		)
+		return
+	}
+
+	c.running.Add(1)
+	err := c.pool.Submit(func() {
+		defer func() {
+			job.running.Store(false)
+			c.running.Done()
+		}()
+
+		job.fn(c.ctx)
+	})
+
+	if err != nil {
+		job.running.Store(false)
+		c.running.Done()
+		logger.Error("cron job submit failed",
+			zap.Int64("Id", job.id),
+			zap.String("Name", job.name),
+			zap.NamedError("Error", err),
+		)
+	}
+}
+
+func (c *Cron) notify() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (cj *cronJob) entry() CronEntry {
+	return CronEntry{
+		Id:      cj.id,
+		Name:    cj.name,
+		Spec:    cj.spec,
+		Prev:    cj.prev,
+		Next:    cj.fireAt,
+		Running: cj.running.Load(),
+	}
+}
@@ -0,0 +1,348 @@
+package components
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cron表达式：
+// 5段：分 时 日 月 周
+// 6段：秒 分 时 日 月 周
+// 每段支持 * ? a a-b a-b/s */s a/s 以及逗号分隔的列表，月和周支持JAN-DEC、SUN-SAT，周的7等同于0
+// 描述符：@yearly @annually @monthly @weekly @daily @midnight @hourly @every <duration>
+// 前缀TZ=<时区>或CRON_TZ=<时区>指定时区，例如"TZ=Asia/Shanghai 0 8 * * *"
+
+var (
+	ErrCronSpec = errors.New("invalid cron spec")
+)
+
+// CronSchedule 计算下次执行时间
+type CronSchedule interface {
+	// Next 返回t之后的下次执行时间，没有时返回零值
+	Next(t time.Time) time.Time
+}
+
+type cronBounds struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	cronSecond = cronBounds{min: 0, max: 59}
+	cronMinute = cronBounds{min: 0, max: 59}
+	cronHour   = cronBounds{min: 0, max: 23}
+	cronDom    = cronBounds{min: 1, max: 31}
+	cronMonth  = cronBounds{min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	cronDow = cronBounds{min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+const (
+	// cronStar 字段为*或?，日和周同时限定时按"或"匹配
+	cronStar = 1 << 63
+)
+
+type cronSpec struct {
+	second, minute, hour, dom, month, dow uint64
+	location                              *time.Location
+}
+
+type everySchedule struct {
+	delay time.Duration
+}
+
+func (es everySchedule) Next(t time.Time) time.Time {
+	return t.Add(es.delay)
+}
+
+// ParseCron 解析cron表达式
+func ParseCron(spec string) (CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	var location *time.Location
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		index := strings.IndexByte(spec, ' ')
+		if index < 0 {
+			return nil, ErrCronSpec
+		}
+
+		loc, err := time.LoadLocation(spec[strings.IndexByte(spec, '=')+1 : index])
+		if err != nil {
+			return nil, err
+		}
+
+		location = loc
+		spec = strings.TrimSpace(spec[index:])
+	}
+
+	if strings.HasPrefix(spec, "@") {
+		return parseCronDescriptor(spec, location)
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, ErrCronSpec
+	}
+
+	var (
+		s      = &cronSpec{location: location}
+		err    error
+		bounds = []cronBounds{cronSecond, cronMinute, cronHour, cronDom, cronMonth, cronDow}
+		bits   = []*uint64{&s.second, &s.minute, &s.hour, &s.dom, &s.month, &s.dow}
+	)
+
+	for index, field := range fields {
+		if *bits[index], err = parseCronField(field, bounds[index]); err != nil {
+			return nil, err
+		}
+	}
+
+	// 周日可以写作0或7
+	if s.dow&(1<<7) > 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+
+	return s, nil
+}
+
+func parseCronDescriptor(spec string, location *time.Location) (CronSchedule, error) {
+	if strings.HasPrefix(spec, "@every ") {
+		delay, err := time.ParseDuration(strings.TrimSpace(spec[len("@every "):]))
+		if err != nil || delay <= 0 {
+			return nil, ErrCronSpec
+		}
+		return everySchedule{delay: delay}, nil
+	}
+
+	var fields string
+	switch spec {
+	case "@yearly", "@annually":
+		fields = "0 0 0 1 1 *"
+	case "@monthly":
+		fields = "0 0 0 1 * *"
+	case "@weekly":
+		fields = "0 0 0 * * 0"
+	case "@daily", "@midnight":
+		fields = "0 0 0 * * *"
+	case "@hourly":
+		fields = "0 0 * * * *"
+	default:
+		return nil, ErrCronSpec
+	}
+
+	schedule, err := ParseCron(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.(*cronSpec).location = location
+	return schedule, nil
+}
+
+func parseCronField(field string, bounds cronBounds) (bits uint64, err error) {
+	for _, expr := range strings.Split(field, ",") {
+		value, err := parseCronRange(expr, bounds)
+		if err != nil {
+			return 0, err
+		}
+		bits |= value
+	}
+
+	return bits, nil
+}
+
+func parseCronRange(expr string, bounds cronBounds) (uint64, error) {
+	var (
+		start, end, step uint = 0, 0, 1
+		extra            uint64
+		rangeAndStep     = strings.Split(expr, "/")
+		lowAndHigh       = strings.Split(rangeAndStep[0], "-")
+		err              error
+	)
+
+	if len(rangeAndStep) > 2 || len(lowAndHigh) > 2 {
+		return 0, ErrCronSpec
+	}
+
+	if lowAndHigh[0] == "*" || lowAndHigh[0] == "?" {
+		if len(lowAndHigh) > 1 {
+			return 0, ErrCronSpec
+		}
+
+		start, end = bounds.min, bounds.max
+		if len(rangeAndStep) == 1 {
+			extra = cronStar
+		}
+	} else {
+		if start, err = parseCronValue(lowAndHigh[0], bounds); err != nil {
+			return 0, err
+		}
+
+		end = start
+		if len(lowAndHigh) == 2 {
+			if end, err = parseCronValue(lowAndHigh[1], bounds); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if len(rangeAndStep) == 2 {
+		value, err := strconv.ParseUint(rangeAndStep[1], 10, 8)
+		if err != nil || value == 0 {
+			return 0, ErrCronSpec
+		}
+
+		step = uint(value)
+		// a/s 表示从a开始到最大值
+		if len(lowAndHigh) == 1 && lowAndHigh[0] != "*" && lowAndHigh[0] != "?" {
+			end = bounds.max
+		}
+	}
+
+	if start < bounds.min || end > bounds.max || start > end {
+		return 0, ErrCronSpec
+	}
+
+	var bits uint64
+	for value := start; value <= end; value += step {
+		bits |= 1 << value
+	}
+
+	return bits | extra, nil
+}
+
+func parseCronValue(value string, bounds cronBounds) (uint, error) {
+	if bounds.names != nil {
+		if num, exists := bounds.names[strings.ToLower(value)]; exists {
+			return num, nil
+		}
+	}
+
+	num, err := strconv.ParseUint(value, 10, 8)
+	if err != nil {
+		return 0, ErrCronSpec
+	}
+
+	return uint(num), nil
+}
+
+// Next 返回t之后的下次执行时间，5年内没有匹配时返回零值
+func (s *cronSpec) Next(t time.Time) time.Time {
+	var (
+		origin = t.Location()
+		loc    = origin
+	)
+
+	if s.location != nil {
+		loc = s.location
+		t = t.In(loc)
+	}
+
+	// 从下一秒开始匹配
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+
+	var (
+		added     bool
+		yearLimit = t.Year() + 5
+	)
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for 1<<uint(t.Month())&s.month == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		}
+
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !s.dayMatches(t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		}
+
+		t = t.AddDate(0, 0, 1)
+		// 夏令时切换可能导致零点不存在，修正到当天开始
+		if t.Hour() != 0 {
+			if t.Hour() > 12 {
+				t = t.Add(time.Duration(24-t.Hour()) * time.Hour)
+			} else {
+				t = t.Add(time.Duration(-t.Hour()) * time.Hour)
+			}
+		}
+
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for 1<<uint(t.Hour())&s.hour == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		}
+
+		t = t.Add(time.Hour)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for 1<<uint(t.Minute())&s.minute == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	for 1<<uint(t.Second())&s.second == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Second)
+		}
+
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+
+	return t.In(origin)
+}
+
+func (s *cronSpec) dayMatches(t time.Time) bool {
+	var (
+		domMatch = 1<<uint(t.Day())&s.dom > 0
+		dowMatch = 1<<uint(t.Weekday())&s.dow > 0
+	)
+
+	// 日和周都被限定时满足其一即可
+	if s.dom&cronStar > 0 || s.dow&cronStar > 0 {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
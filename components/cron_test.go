@@ -0,0 +1,188 @@
+package components
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grpc-boot/base/v3/gopool"
+
+	"go.uber.org/atomic"
+)
+
+func TestParseCron(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("load location failed: %v", err)
+	}
+
+	from := time.Date(2024, 2, 28, 23, 59, 30, 0, time.UTC)
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"*/15 * * * * *", time.Date(2024, 2, 28, 23, 59, 45, 0, time.UTC)},
+		{"0 9 * * MON-FRI", time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC)},
+		{"30 8 1 */2 *", time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)},
+		{"0 0 28 2 *", time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{"0 12 13 * 5", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2024, 2, 29, 0, 5, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", time.Date(2024, 2, 29, 0, 1, 0, 0, time.UTC)},
+		{"TZ=Asia/Shanghai 0 8 * * *", time.Date(2024, 2, 29, 8, 0, 0, 0, shanghai)},
+	}
+
+	for _, c := range cases {
+		schedule, err := ParseCron(c.spec)
+		if err != nil {
+			t.Fatalf("%s: want nil, got %v", c.spec, err)
+		}
+
+		if next := schedule.Next(from); !next.Equal(c.want) {
+			t.Fatalf("%s: want %v, got %v", c.spec, c.want, next)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "@weekday", "@every -1s", "TZ=Mars/Base * * * * *"} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Fatalf("%s: want error, got nil", spec)
+		}
+	}
+
+	// 2月30日永远不会到达
+	schedule, _ := ParseCron("0 0 30 2 *")
+	if next := schedule.Next(from); !next.IsZero() {
+		t.Fatalf("want zero, got %v", next)
+	}
+}
+
+func TestCron(t *testing.T) {
+	pool, _ := gopool.NewPool(4)
+
+	var (
+		c        = NewCron(pool)
+		fast     atomic.Int32
+		slow     atomic.Int32
+		canceled atomic.Bool
+	)
+
+	fastId, err := c.Add("fast", "@every 20ms", func(ctx context.Context) {
+		fast.Inc()
+	})
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	// 执行时间超过间隔时不会重叠执行
+	_, _ = c.Add("slow", "@every 10ms", func(ctx context.Context) {
+		slow.Inc()
+		select {
+		case <-time.After(time.Millisecond * 100):
+		case <-ctx.Done():
+			canceled.Store(true)
+		}
+	})
+
+	entry, err := c.Entry(fastId)
+	if err != nil || entry.Name != "fast" || entry.Next.IsZero() {
+		t.Fatalf("want fast entry, got %+v %v", entry, err)
+	}
+
+	c.Start()
+	time.Sleep(time.Millisecond * 150)
+
+	if n := fast.Load(); n < 3 {
+		t.Fatalf("want fast >= 3, got %d", n)
+	}
+
+	if n := slow.Load(); n > 2 {
+		t.Fatalf("want slow <= 2, got %d", n)
+	}
+
+	if entries := c.Entries(); len(entries) != 2 || entries[0].Next.After(entries[1].Next) {
+		t.Fatalf("want sorted entries, got %+v", entries)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	if err = c.StopWithContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("want context.DeadlineExceeded, got %v", err)
+	}
+
+	if err = c.Stop(); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if !canceled.Load() {
+		t.Fatal("want job ctx canceled")
+	}
+
+	count := fast.Load()
+	time.Sleep(time.Millisecond * 50)
+	if fast.Load() != count {
+		t.Fatalf("want no run after stop, got %d", fast.Load()-count)
+	}
+
+	if _, err = c.Add("late", "@hourly", func(ctx context.Context) {}); err != ErrCronStopped {
+		t.Fatalf("want ErrCronStopped, got %v", err)
+	}
+}
+
+func TestCron_SaturatedPool(t *testing.T) {
+	pool, _ := gopool.NewPool(1)
+
+	var (
+		c    = NewCron(pool)
+		fast atomic.Int32
+	)
+
+	// 唯一的worker被占用，调度循环阻塞在Submit
+	_, _ = c.Add("block", "@every 10ms", func(ctx context.Context) {
+		<-ctx.Done()
+	})
+	_, _ = c.Add("fast", "@every 10ms", func(ctx context.Context) {
+		fast.Inc()
+	})
+
+	c.Start()
+	time.Sleep(time.Millisecond * 50)
+
+	entries := make(chan []CronEntry, 1)
+	go func() {
+		entries <- c.Entries()
+	}()
+
+	select {
+	case list := <-entries:
+		if len(list) != 2 {
+			t.Fatalf("want 2 entries, got %d", len(list))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("want Entries not blocked by full pool")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- c.StopWithContext(ctx)
+	}()
+
+	select {
+	case err := <-stopped:
+		if err != context.DeadlineExceeded {
+			t.Fatalf("want context.DeadlineExceeded, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("want StopWithContext return after deadline")
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+}
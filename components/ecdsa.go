@@ -0,0 +1,148 @@
+package components
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+)
+
+var (
+	ErrEcdsaKey   = errors.New("not a ecdsa key")
+	ErrEcdsaCurve = errors.New("ecdsa curve must be P-256 or P-384")
+)
+
+// Ecdsa ECDSA签名，支持P-256和P-384，签名为ASN.1 DER格式
+type Ecdsa struct {
+	privateKey *ecdsa.PrivateKey
+	publicKey  *ecdsa.PublicKey
+}
+
+// NewEcdsa 实例化Ecdsa，私钥为pkcs8或SEC 1(EC PRIVATE KEY)格式
+func NewEcdsa(publicKey, privateKey string) (e *Ecdsa, err error) {
+	return NewEcdsaBytes([]byte(publicKey), []byte(privateKey))
+}
+
+// NewEcdsaBytes 实例化Ecdsa，私钥为pkcs8或SEC 1(EC PRIVATE KEY)格式
+func NewEcdsaBytes(public, private []byte) (e *Ecdsa, err error) {
+	e = &Ecdsa{}
+
+	if len(private) > 0 {
+		block, _ := pem.Decode(private)
+		if block == nil {
+			return nil, ErrPemData
+		}
+
+		if block.Type == "EC PRIVATE KEY" {
+			e.privateKey, err = x509.ParseECPrivateKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+
+			var ok bool
+			if e.privateKey, ok = key.(*ecdsa.PrivateKey); !ok {
+				return nil, ErrEcdsaKey
+			}
+		}
+
+		e.publicKey = &e.privateKey.PublicKey
+	}
+
+	if len(public) > 0 {
+		key, err := parsePkixPublicKey(public)
+		if err != nil {
+			return nil, err
+		}
+
+		var ok bool
+		if e.publicKey, ok = key.(*ecdsa.PublicKey); !ok {
+			return nil, ErrEcdsaKey
+		}
+	}
+
+	if e.publicKey != nil && !supportedCurve(e.publicKey.Curve) {
+		return nil, ErrEcdsaCurve
+	}
+
+	return e, nil
+}
+
+func (e *Ecdsa) Algorithm() string {
+	return AlgorithmEcdsa
+}
+
+// PublicKey 公钥
+func (e *Ecdsa) PublicKey() *ecdsa.PublicKey {
+	return e.publicKey
+}
+
+// PrivateKey 私钥
+func (e *Ecdsa) PrivateKey() *ecdsa.PrivateKey {
+	return e.privateKey
+}
+
+// Sign 数据签名
+func (e *Ecdsa) Sign(data []byte, algorithmSign crypto.Hash) ([]byte, error) {
+	if e.privateKey == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	algorithmSign, ok := signHash(algorithmSign, e.defaultHash())
+	if !ok {
+		return nil, ErrSignAlgorithm
+	}
+
+	h := algorithmSign.New()
+	h.Write(data)
+	return ecdsa.SignASN1(rand.Reader, e.privateKey, h.Sum(nil))
+}
+
+// Verify 数据验签
+func (e *Ecdsa) Verify(data []byte, sign []byte, algorithmSign crypto.Hash) bool {
+	if e.publicKey == nil {
+		return false
+	}
+
+	algorithmSign, ok := signHash(algorithmSign, e.defaultHash())
+	if !ok {
+		return false
+	}
+
+	h := algorithmSign.New()
+	h.Write(data)
+	return ecdsa.VerifyASN1(e.publicKey, h.Sum(nil), sign)
+}
+
+// defaultHash 与曲线强度匹配的hash
+func (e *Ecdsa) defaultHash() crypto.Hash {
+	if e.publicKey.Curve == elliptic.P384() {
+		return crypto.SHA384
+	}
+	return crypto.SHA256
+}
+
+// CreateEcdsaKeys 生成pkcs8格式私钥和公钥，curve为elliptic.P256()或elliptic.P384()
+func CreateEcdsaKeys(curve elliptic.Curve) (privateKey, publicKey string) {
+	if !supportedCurve(curve) {
+		return
+	}
+
+	priKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return
+	}
+
+	return marshalPkcs8Keys(priKey, &priKey.PublicKey)
+}
+
+func supportedCurve(curve elliptic.Curve) bool {
+	return curve == elliptic.P256() || curve == elliptic.P384()
+}
@@ -0,0 +1,152 @@
+package components
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+)
+
+var (
+	ErrEd25519Key = errors.New("not a ed25519 key")
+)
+
+// Ed25519 Ed25519签名
+type Ed25519 struct {
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// NewEd25519 实例化Ed25519，私钥为pkcs8格式
+func NewEd25519(publicKey, privateKey string) (e *Ed25519, err error) {
+	return NewEd25519Bytes([]byte(publicKey), []byte(privateKey))
+}
+
+// NewEd25519Bytes 实例化Ed25519，私钥为pkcs8格式
+func NewEd25519Bytes(public, private []byte) (e *Ed25519, err error) {
+	e = &Ed25519{}
+
+	if len(private) > 0 {
+		key, err := parsePkcs8PrivateKey(private)
+		if err != nil {
+			return nil, err
+		}
+
+		var ok bool
+		if e.privateKey, ok = key.(ed25519.PrivateKey); !ok {
+			return nil, ErrEd25519Key
+		}
+		e.publicKey = e.privateKey.Public().(ed25519.PublicKey)
+	}
+
+	if len(public) > 0 {
+		key, err := parsePkixPublicKey(public)
+		if err != nil {
+			return nil, err
+		}
+
+		var ok bool
+		if e.publicKey, ok = key.(ed25519.PublicKey); !ok {
+			return nil, ErrEd25519Key
+		}
+	}
+
+	return e, nil
+}
+
+func (e *Ed25519) Algorithm() string {
+	return AlgorithmEd25519
+}
+
+// PublicKey 公钥
+func (e *Ed25519) PublicKey() ed25519.PublicKey {
+	return e.publicKey
+}
+
+// PrivateKey 私钥
+func (e *Ed25519) PrivateKey() ed25519.PrivateKey {
+	return e.privateKey
+}
+
+// Sign 数据签名，algorithmSign为SHA512时使用Ed25519ph，其他值使用Ed25519
+func (e *Ed25519) Sign(data []byte, algorithmSign crypto.Hash) ([]byte, error) {
+	if e.privateKey == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	if algorithmSign != crypto.SHA512 {
+		return ed25519.Sign(e.privateKey, data), nil
+	}
+
+	h := algorithmSign.New()
+	h.Write(data)
+	return e.privateKey.Sign(rand.Reader, h.Sum(nil), &ed25519.Options{Hash: crypto.SHA512})
+}
+
+// Verify 数据验签
+func (e *Ed25519) Verify(data []byte, sign []byte, algorithmSign crypto.Hash) bool {
+	if e.publicKey == nil {
+		return false
+	}
+
+	if algorithmSign != crypto.SHA512 {
+		return ed25519.Verify(e.publicKey, data, sign)
+	}
+
+	h := algorithmSign.New()
+	h.Write(data)
+	return ed25519.VerifyWithOptions(e.publicKey, h.Sum(nil), sign, &ed25519.Options{Hash: crypto.SHA512}) == nil
+}
+
+// CreateEd25519Keys 生成pkcs8格式私钥和公钥
+func CreateEd25519Keys() (privateKey, publicKey string) {
+	pubKey, priKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return
+	}
+
+	return marshalPkcs8Keys(priKey, pubKey)
+}
+
+func marshalPkcs8Keys(priKey, pubKey any) (privateKey, publicKey string) {
+	derPkcs8, err := x509.MarshalPKCS8PrivateKey(priKey)
+	if err != nil {
+		return
+	}
+
+	derPkix, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		return
+	}
+
+	privateKey = string(pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: derPkcs8,
+	}))
+
+	publicKey = string(pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: derPkix,
+	}))
+	return
+}
+
+func parsePkcs8PrivateKey(private []byte) (any, error) {
+	block, _ := pem.Decode(private)
+	if block == nil {
+		return nil, ErrPemData
+	}
+
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
+
+func parsePkixPublicKey(public []byte) (any, error) {
+	block, _ := pem.Decode(public)
+	if block == nil {
+		return nil, ErrPemData
+	}
+
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
@@ -6,8 +6,11 @@ var (
 	ErrDataEmpty          = errors.New("data is empty")
 	ErrDataFormat         = errors.New("invalid data format")
 	ErrOutOfRange         = errors.New("out of range")
+	ErrCheckChar          = errors.New("check char mismatch")
+	ErrCodeVersion        = errors.New("unknown code version")
 	ErrAlphanumeric       = errors.New("alphanumeric must be [a-zA-Z0-9] and not repeat")
 	ErrAlphanumericLength = errors.New("alphanumeric length must be [50, 62]")
+	ErrCharset            = errors.New("charset must be [2, 256] bytes and not repeat")
 	ErrTimeBack           = errors.New("time go back")
 	ErrMachineId          = errors.New("illegal machine id")
 )
@@ -0,0 +1,441 @@
+package components
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grpc-boot/base/v3/logger"
+	"github.com/grpc-boot/base/v3/utils"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+const (
+	FlagOn  = `on`
+	FlagOff = `off`
+)
+
+const (
+	FlagReasonNotFound = `not_found`
+	FlagReasonDisabled = `disabled`
+	FlagReasonRule     = `rule`
+	FlagReasonDefault  = `default`
+)
+
+const (
+	FlagOpEq       = `eq`
+	FlagOpNeq      = `neq`
+	FlagOpIn       = `in`
+	FlagOpNotIn    = `not_in`
+	FlagOpContains = `contains`
+	FlagOpPrefix   = `prefix`
+	FlagOpSuffix   = `suffix`
+	FlagOpGt       = `gt`
+	FlagOpGte      = `gte`
+	FlagOpLt       = `lt`
+	FlagOpLte      = `lte`
+)
+
+const (
+	// flagBuckets 分桶数，百分比精确到0.01%
+	flagBuckets = 10000
+)
+
+var (
+	ErrFlagDefinition = errors.New("invalid feature flag definition")
+)
+
+// FlagDefinitions 特性开关定义文件
+type FlagDefinitions struct {
+	Flags map[string]*Flag `json:"flags" yaml:"flags"`
+}
+
+// Flag 特性开关，按顺序匹配Rules，第一个命中的规则决定变体，都不命中时使用Default(为空时为off)
+type Flag struct {
+	Enabled bool       `json:"enabled" yaml:"enabled"`
+	Salt    string     `json:"salt" yaml:"salt"`
+	Default string     `json:"default" yaml:"default"`
+	Rules   []FlagRule `json:"rules" yaml:"rules"`
+}
+
+// FlagRule 规则，Users、Attributes和Percentage同时满足时命中，Split不为空时按权重分配变体，否则为Variant(为空时为on)
+type FlagRule struct {
+	Name       string        `json:"name" yaml:"name"`
+	Users      []string      `json:"users" yaml:"users"`
+	Attributes []FlagMatch   `json:"attributes" yaml:"attributes"`
+	Percentage *float64      `json:"percentage" yaml:"percentage"`
+	Variant    string        `json:"variant" yaml:"variant"`
+	Split      []FlagVariant `json:"split" yaml:"split"`
+}
+
+// FlagMatch 属性匹配，Attribute为FlagContext.Attributes中的key
+type FlagMatch struct {
+	Attribute string   `json:"attribute" yaml:"attribute"`
+	Operator  string   `json:"operator" yaml:"operator"`
+	Values    []string `json:"values" yaml:"values"`
+}
+
+// FlagVariant A/B实验变体及权重
+type FlagVariant struct {
+	Name   string `json:"name" yaml:"name"`
+	Weight int    `json:"weight" yaml:"weight"`
+}
+
+// FlagContext 求值上下文，UserId用于白名单和分桶
+type FlagContext struct {
+	UserId     string
+	Attributes map[string]any
+}
+
+// FlagResult 求值结果
+type FlagResult struct {
+	Key     string
+	Variant string
+	Rule    string
+	Reason  string
+}
+
+// Enabled 变体不为off时视为开启
+func (fr FlagResult) Enabled() bool {
+	return fr.Variant != FlagOff
+}
+
+// FlagLoader 加载特性开关定义
+type FlagLoader func() (*FlagDefinitions, error)
+
+// YamlFlagLoader 从Yaml文件加载定义
+func YamlFlagLoader(filePath string) FlagLoader {
+	return func() (*FlagDefinitions, error) {
+		defs := &FlagDefinitions{}
+		if err := utils.YamlUnmarshalFile(filePath, defs); err != nil {
+			return nil, err
+		}
+		return defs, nil
+	}
+}
+
+// JsonFlagLoader 从Json文件加载定义
+func JsonFlagLoader(filePath string) FlagLoader {
+	return func() (*FlagDefinitions, error) {
+		defs := &FlagDefinitions{}
+		if err := utils.JsonUnmarshalFile(filePath, defs); err != nil {
+			return nil, err
+		}
+		return defs, nil
+	}
+}
+
+// FeatureFlags 特性开关求值，分桶使用utils.HashValue(key:salt:userId)，不同实例结果一致，热加载时原子替换定义，读取不加锁
+type FeatureFlags struct {
+	loader    FlagLoader
+	current   atomic.Pointer[FlagDefinitions]
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewFeatureFlags 实例化特性开关
+func NewFeatureFlags(loader FlagLoader) (*FeatureFlags, error) {
+	ff := &FeatureFlags{
+		loader: loader,
+		done:   make(chan struct{}),
+	}
+
+	if err := ff.Reload(); err != nil {
+		return nil, err
+	}
+
+	return ff, nil
+}
+
+// Reload 重新加载定义，加载或校验失败时保留原定义
+func (ff *FeatureFlags) Reload() error {
+	defs, err := ff.loader()
+	if err != nil {
+		return err
+	}
+
+	if defs == nil {
+		return ErrFlagDefinition
+	}
+
+	for key, flag := range defs.Flags {
+		if err = validateFlag(key, flag); err != nil {
+			return err
+		}
+	}
+
+	ff.current.Store(defs)
+	return nil
+}
+
+// Watch 每隔interval检查定义文件的大小和修改时间，有变化时重新加载，调用Close停止
+func (ff *FeatureFlags) Watch(filePath string, interval time.Duration) {
+	last, _ := pathSignature(filePath)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ff.done:
+				return
+			case <-ticker.C:
+			}
+
+			sign, err := pathSignature(filePath)
+			if err != nil {
+				logger.Error("feature flags watch failed",
+					zap.String("Path", filePath),
+					zap.NamedError("Error", err),
+				)
+				continue
+			}
+
+			if sign == last {
+				continue
+			}
+
+			if err = ff.Reload(); err != nil {
+				logger.Error("feature flags reload failed",
+					zap.String("Path", filePath),
+					zap.NamedError("Error", err),
+				)
+				continue
+			}
+
+			last = sign
+		}
+	}()
+}
+
+// Close 停止Watch
+func (ff *FeatureFlags) Close() {
+	ff.closeOnce.Do(func() {
+		close(ff.done)
+	})
+}
+
+// Keys 所有开关的key
+func (ff *FeatureFlags) Keys() []string {
+	defs := ff.current.Load()
+	keys := make([]string, 0, len(defs.Flags))
+	for key := range defs.Flags {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// Enabled 开关是否开启
+func (ff *FeatureFlags) Enabled(key string, fc *FlagContext) bool {
+	return ff.Evaluate(key, fc).Enabled()
+}
+
+// Variant 开关的变体
+func (ff *FeatureFlags) Variant(key string, fc *FlagContext) string {
+	return ff.Evaluate(key, fc).Variant
+}
+
+// Evaluate 对开关求值
+func (ff *FeatureFlags) Evaluate(key string, fc *FlagContext) FlagResult {
+	result := FlagResult{Key: key, Variant: FlagOff}
+
+	flag, exists := ff.current.Load().Flags[key]
+	if !exists || flag == nil {
+		result.Reason = FlagReasonNotFound
+		return result
+	}
+
+	if !flag.Enabled {
+		result.Reason = FlagReasonDisabled
+		return result
+	}
+
+	if fc == nil {
+		fc = &FlagContext{}
+	}
+
+	salt := flag.Salt
+	if salt == "" {
+		salt = key
+	}
+
+	for index := range flag.Rules {
+		rule := &flag.Rules[index]
+		if !rule.match(key, salt, fc) {
+			continue
+		}
+
+		result.Rule = rule.Name
+		result.Reason = FlagReasonRule
+		result.Variant = rule.variant(key, salt, fc)
+		return result
+	}
+
+	result.Reason = FlagReasonDefault
+	if flag.Default != "" {
+		result.Variant = flag.Default
+	}
+
+	return result
+}
+
+// FlagBucket 用户在开关中的分桶[0, 10000)，相同的key、salt和userId在任何实例上结果相同
+func FlagBucket(key, salt, userId string) uint32 {
+	return utils.HashValue(key+":"+salt+":"+userId) % flagBuckets
+}
+
+func (fr *FlagRule) match(key, salt string, fc *FlagContext) bool {
+	if len(fr.Users) > 0 {
+		found := false
+		for _, user := range fr.Users {
+			if user == fc.UserId {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	for _, fm := range fr.Attributes {
+		if !fm.match(fc.Attributes) {
+			return false
+		}
+	}
+
+	if fr.Percentage != nil {
+		if fc.UserId == "" {
+			return false
+		}
+
+		return float64(FlagBucket(key, salt, fc.UserId)) < *fr.Percentage*flagBuckets/100
+	}
+
+	return true
+}
+
+func (fr *FlagRule) variant(key, salt string, fc *FlagContext) string {
+	if len(fr.Split) == 0 {
+		if fr.Variant == "" {
+			return FlagOn
+		}
+		return fr.Variant
+	}
+
+	total := 0
+	for _, fv := range fr.Split {
+		total += fv.Weight
+	}
+
+	// 变体分桶与百分比分桶相互独立
+	point := int(FlagBucket(key, salt+":variant", fc.UserId)) * total / flagBuckets
+	for _, fv := range fr.Split {
+		if point < fv.Weight {
+			return fv.Name
+		}
+		point -= fv.Weight
+	}
+
+	return fr.Split[len(fr.Split)-1].Name
+}
+
+func (fm *FlagMatch) match(attributes map[string]any) bool {
+	raw, exists := attributes[fm.Attribute]
+	if !exists {
+		return fm.Operator == FlagOpNeq || fm.Operator == FlagOpNotIn
+	}
+
+	value := fmt.Sprint(raw)
+	switch fm.Operator {
+	case FlagOpEq, FlagOpIn:
+		return fm.any(func(expected string) bool { return value == expected })
+	case FlagOpNeq, FlagOpNotIn:
+		return !fm.any(func(expected string) bool { return value == expected })
+	case FlagOpContains:
+		return fm.any(func(expected string) bool { return strings.Contains(value, expected) })
+	case FlagOpPrefix:
+		return fm.any(func(expected string) bool { return strings.HasPrefix(value, expected) })
+	case FlagOpSuffix:
+		return fm.any(func(expected string) bool { return strings.HasSuffix(value, expected) })
+	}
+
+	num, err := strconv.ParseFloat(value, 64)
+	if err != nil || len(fm.Values) < 1 {
+		return false
+	}
+
+	expected, err := strconv.ParseFloat(fm.Values[0], 64)
+	if err != nil {
+		return false
+	}
+
+	switch fm.Operator {
+	case FlagOpGt:
+		return num > expected
+	case FlagOpGte:
+		return num >= expected
+	case FlagOpLt:
+		return num < expected
+	case FlagOpLte:
+		return num <= expected
+	}
+
+	return false
+}
+
+func (fm *FlagMatch) any(handler func(expected string) bool) bool {
+	for _, expected := range fm.Values {
+		if handler(expected) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateFlag(key string, flag *Flag) error {
+	if flag == nil {
+		return fmt.Errorf("%w: %s is empty", ErrFlagDefinition, key)
+	}
+
+	for _, rule := range flag.Rules {
+		if rule.Percentage != nil && (*rule.Percentage < 0 || *rule.Percentage > 100) {
+			return fmt.Errorf("%w: %s percentage must be [0, 100]", ErrFlagDefinition, key)
+		}
+
+		for _, fm := range rule.Attributes {
+			switch fm.Operator {
+			case FlagOpEq, FlagOpNeq, FlagOpIn, FlagOpNotIn, FlagOpContains, FlagOpPrefix, FlagOpSuffix:
+			case FlagOpGt, FlagOpGte, FlagOpLt, FlagOpLte:
+				if len(fm.Values) != 1 {
+					return fmt.Errorf("%w: %s operator %s needs one value", ErrFlagDefinition, key, fm.Operator)
+				}
+
+				if _, err := strconv.ParseFloat(fm.Values[0], 64); err != nil {
+					return fmt.Errorf("%w: %s operator %s needs number", ErrFlagDefinition, key, fm.Operator)
+				}
+			default:
+				return fmt.Errorf("%w: %s unknown operator %s", ErrFlagDefinition, key, fm.Operator)
+			}
+		}
+
+		for _, fv := range rule.Split {
+			if fv.Name == "" || fv.Weight <= 0 {
+				return fmt.Errorf("%w: %s split variant needs name and positive weight", ErrFlagDefinition, key)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package components
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const flagsYaml = `
+flags:
+  new_checkout:
+    enabled: true
+    rules:
+      - name: staff
+        users: [u1, u2]
+      - name: vip
+        attributes:
+          - attribute: level
+            operator: gte
+            values: ["3"]
+          - attribute: country
+            operator: in
+            values: [CN, SG]
+      - name: rollout
+        percentage: 20
+  search_ranker:
+    enabled: true
+    default: control
+    rules:
+      - name: experiment
+        split:
+          - name: control
+            weight: 50
+          - name: bm25
+            weight: 30
+          - name: vector
+            weight: 20
+  legacy:
+    enabled: false
+    rules:
+      - name: all
+`
+
+func writeFlags(t *testing.T, file, content string) {
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFeatureFlags_Evaluate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "flags.yml")
+	writeFlags(t, file, flagsYaml)
+
+	ff, err := NewFeatureFlags(YamlFlagLoader(file))
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if result := ff.Evaluate("new_checkout", &FlagContext{UserId: "u2"}); !result.Enabled() || result.Rule != "staff" {
+		t.Fatalf("want staff rule, got %+v", result)
+	}
+
+	vip := &FlagContext{UserId: "guest", Attributes: map[string]any{"level": 5, "country": "SG"}}
+	if result := ff.Evaluate("new_checkout", vip); result.Rule != "vip" || result.Variant != FlagOn {
+		t.Fatalf("want vip rule, got %+v", result)
+	}
+
+	if result := ff.Evaluate("legacy", nil); result.Enabled() || result.Reason != FlagReasonDisabled {
+		t.Fatalf("want disabled, got %+v", result)
+	}
+
+	if result := ff.Evaluate("missing", nil); result.Enabled() || result.Reason != FlagReasonNotFound {
+		t.Fatalf("want not found, got %+v", result)
+	}
+
+	// 百分比放量结果稳定且接近配置比例
+	var (
+		total    = 20000
+		enabled  int
+		variants = map[string]int{}
+	)
+
+	for i := 0; i < total; i++ {
+		fc := &FlagContext{UserId: "user-" + strconv.Itoa(i)}
+		first := ff.Enabled("new_checkout", fc)
+		if first != ff.Enabled("new_checkout", fc) {
+			t.Fatal("want deterministic bucketing")
+		}
+
+		if first {
+			enabled++
+		}
+		variants[ff.Variant("search_ranker", fc)]++
+	}
+
+	if rate := float64(enabled) / float64(total); math.Abs(rate-0.2) > 0.02 {
+		t.Fatalf("want about 20%%, got %f", rate)
+	}
+
+	for name, weight := range map[string]float64{"control": 0.5, "bm25": 0.3, "vector": 0.2} {
+		if rate := float64(variants[name]) / float64(total); math.Abs(rate-weight) > 0.02 {
+			t.Fatalf("want %s about %f, got %f", name, weight, rate)
+		}
+	}
+
+	if bucket := FlagBucket("new_checkout", "new_checkout", "user-1"); bucket != FlagBucket("new_checkout", "new_checkout", "user-1") || bucket >= 10000 {
+		t.Fatalf("want stable bucket, got %d", bucket)
+	}
+}
+
+func TestFeatureFlags_Reload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "flags.json")
+	writeFlags(t, file, `{"flags": {"dark_mode": {"enabled": true, "rules": [{"percentage": 0}]}}}`)
+
+	ff, err := NewFeatureFlags(JsonFlagLoader(file))
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	ff.Watch(file, time.Millisecond*10)
+	defer ff.Close()
+
+	fc := &FlagContext{UserId: "u1"}
+	if ff.Enabled("dark_mode", fc) {
+		t.Fatal("want disabled at 0%")
+	}
+
+	// 校验失败时保留原定义
+	writeFlags(t, file, `{"flags": {"dark_mode": {"enabled": true, "rules": [{"percentage": 120}]}}}`)
+	if err = ff.Reload(); !errors.Is(err, ErrFlagDefinition) {
+		t.Fatalf("want ErrFlagDefinition, got %v", err)
+	}
+
+	writeFlags(t, file, `{"flags": {"dark_mode": {"enabled": true, "rules": [{"percentage": 100}]}}}`)
+	_ = os.Chtimes(file, time.Now(), time.Now().Add(time.Second))
+
+	deadline := time.Now().Add(time.Second)
+	for !ff.Enabled("dark_mode", fc) {
+		if time.Now().After(deadline) {
+			t.Fatal("want flags reloaded")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if keys := ff.Keys(); len(keys) != 1 || keys[0] != "dark_mode" {
+		t.Fatalf("want dark_mode, got %v", keys)
+	}
+}
@@ -0,0 +1,99 @@
+package components
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Graphviz 导出为Graphviz dot，初始状态使用双圈
+func (f *Fsm) Graphviz() string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	var sb strings.Builder
+	sb.WriteString("digraph ")
+	sb.WriteString(strconv.Quote(f.name))
+	sb.WriteString(" {\n\trankdir=LR;\n\tnode [shape=circle];\n")
+
+	for _, state := range f.states.Slice() {
+		sb.WriteString("\ts")
+		sb.WriteString(strconv.Itoa(int(state)))
+		sb.WriteString(" [label=")
+		sb.WriteString(strconv.Quote(f.label(state)))
+		if state == f.initial {
+			sb.WriteString(", shape=doublecircle")
+		}
+		sb.WriteString("];\n")
+	}
+
+	f.rangeEdges(func(from, to uint8, event string) {
+		sb.WriteString("\ts")
+		sb.WriteString(strconv.Itoa(int(from)))
+		sb.WriteString(" -> s")
+		sb.WriteString(strconv.Itoa(int(to)))
+		sb.WriteString(" [label=")
+		sb.WriteString(strconv.Quote(event))
+		sb.WriteString("];\n")
+	})
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid 导出为Mermaid stateDiagram-v2
+func (f *Fsm) Mermaid() string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	var sb strings.Builder
+	sb.WriteString("stateDiagram-v2\n")
+
+	for _, state := range f.states.Slice() {
+		sb.WriteString("\ts")
+		sb.WriteString(strconv.Itoa(int(state)))
+		sb.WriteString(" : ")
+		sb.WriteString(mermaidText(f.label(state)))
+		sb.WriteByte('\n')
+	}
+
+	if f.states != 0 {
+		sb.WriteString("\t[*] --> s")
+		sb.WriteString(strconv.Itoa(int(f.initial)))
+		sb.WriteByte('\n')
+	}
+
+	f.rangeEdges(func(from, to uint8, event string) {
+		sb.WriteString("\ts")
+		sb.WriteString(strconv.Itoa(int(from)))
+		sb.WriteString(" --> s")
+		sb.WriteString(strconv.Itoa(int(to)))
+		sb.WriteString(" : ")
+		sb.WriteString(mermaidText(event))
+		sb.WriteByte('\n')
+	})
+
+	return sb.String()
+}
+
+func (f *Fsm) label(state uint8) string {
+	if name := f.names[state]; name != "" {
+		return name
+	}
+
+	return strconv.Itoa(int(state))
+}
+
+// rangeEdges 按声明顺序遍历所有转换边
+func (f *Fsm) rangeEdges(handler func(from, to uint8, event string)) {
+	for _, t := range f.transitions {
+		from := t.from
+		for _, state := range from.Slice() {
+			handler(state, t.to, t.event)
+		}
+	}
+}
+
+// mermaidText 去掉会破坏Mermaid语法的换行和冒号
+func mermaidText(text string) string {
+	return strings.NewReplacer("\n", " ", "\r", " ", ":", " ").Replace(text)
+}
@@ -0,0 +1,361 @@
+package components
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/grpc-boot/base/v3/kind"
+)
+
+const (
+	EventFsmTransition = `fsm.transition`
+)
+
+var (
+	ErrFsmState      = errors.New("fsm state not declared")
+	ErrFsmTransition = errors.New("fsm transition not allowed")
+	ErrFsmGuard      = errors.New("fsm transition rejected by guard")
+	ErrFsmAction     = errors.New("fsm action failed")
+)
+
+// FsmError 状态转换失败，可以用errors.Is判断ErrFsmState、ErrFsmTransition、ErrFsmGuard、ErrFsmAction
+type FsmError struct {
+	Fsm   string
+	Event string
+	From  uint8
+	Err   error
+	Cause error
+}
+
+func (fe *FsmError) Error() string {
+	if fe.Cause != nil {
+		return fmt.Sprintf("%s: %s from %d: %v: %v", fe.Fsm, fe.Event, fe.From, fe.Err, fe.Cause)
+	}
+
+	return fmt.Sprintf("%s: %s from %d: %v", fe.Fsm, fe.Event, fe.From, fe.Err)
+}
+
+func (fe *FsmError) Unwrap() []error {
+	if fe.Cause != nil {
+		return []error{fe.Err, fe.Cause}
+	}
+
+	return []error{fe.Err}
+}
+
+// FsmTransition 一次状态转换，作为guard、action的参数和EventFsmTransition事件数据
+type FsmTransition struct {
+	Fsm   string
+	Event string
+	From  uint8
+	To    uint8
+	Data  any
+}
+
+// FsmGuard 返回false时拒绝转换
+type FsmGuard func(t *FsmTransition) bool
+
+// FsmAction 进入或离开状态时执行，返回错误时转换失败且状态不变
+type FsmAction func(t *FsmTransition) error
+
+type fsmTransition struct {
+	event  string
+	from   kind.State
+	to     uint8
+	guards []FsmGuard
+}
+
+// Fsm 有限状态机定义，状态为kind.State的位索引[0, 30]，定义完成后可被多个FsmMachine共享
+type Fsm struct {
+	mutex       sync.RWMutex
+	name        string
+	states      kind.State
+	names       map[uint8]string
+	initial     uint8
+	transitions []*fsmTransition
+	enter       map[uint8][]FsmAction
+	exit        map[uint8][]FsmAction
+	events      *EventManager
+}
+
+// NewFsm 实例化状态机定义，events不为nil时每次转换成功后触发EventFsmTransition事件
+func NewFsm(name string, events *EventManager) *Fsm {
+	return &Fsm{
+		name:   name,
+		names:  make(map[uint8]string),
+		enter:  make(map[uint8][]FsmAction),
+		exit:   make(map[uint8][]FsmAction),
+		events: events,
+	}
+}
+
+// Name 状态机名称
+func (f *Fsm) Name() string {
+	return f.name
+}
+
+// AddState 声明状态，第一个声明的状态为初始状态
+func (f *Fsm) AddState(state uint8, name string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	first := f.states == 0
+	if err := f.states.Add(state); err != nil {
+		return err
+	}
+
+	if first {
+		f.initial = state
+	}
+
+	f.names[state] = name
+	return nil
+}
+
+// SetInitial 设置初始状态
+func (f *Fsm) SetInitial(state uint8) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if !f.states.Has(state) {
+		return ErrFsmState
+	}
+
+	f.initial = state
+	return nil
+}
+
+// StateName 状态名称
+func (f *Fsm) StateName(state uint8) string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.names[state]
+}
+
+// AddTransition 声明事件event可以将状态从from中的任意一个转换为to，同一事件有多个转换时使用第一个guard全部通过的
+func (f *Fsm) AddTransition(event string, from []uint8, to uint8, guards ...FsmGuard) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if !f.states.Has(to) {
+		return ErrFsmState
+	}
+
+	var fromState kind.State
+	for _, state := range from {
+		if !f.states.Has(state) {
+			return ErrFsmState
+		}
+		_ = fromState.Add(state)
+	}
+
+	f.transitions = append(f.transitions, &fsmTransition{
+		event:  event,
+		from:   fromState,
+		to:     to,
+		guards: guards,
+	})
+
+	return nil
+}
+
+// OnEnter 进入state时执行
+func (f *Fsm) OnEnter(state uint8, actions ...FsmAction) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.enter[state] = append(f.enter[state], actions...)
+}
+
+// OnExit 离开state时执行
+func (f *Fsm) OnExit(state uint8, actions ...FsmAction) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.exit[state] = append(f.exit[state], actions...)
+}
+
+// Events 状态current可以触发的事件
+func (f *Fsm) Events(current uint8) []string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	var (
+		events = make([]string, 0, len(f.transitions))
+		seen   = make(map[string]struct{}, len(f.transitions))
+	)
+
+	for _, t := range f.transitions {
+		if _, exists := seen[t.event]; exists || !t.from.Has(current) {
+			continue
+		}
+
+		seen[t.event] = struct{}{}
+		events = append(events, t.event)
+	}
+
+	return events
+}
+
+// Can 状态current是否可以触发event，不执行guard
+func (f *Fsm) Can(current uint8, event string) bool {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	for _, t := range f.transitions {
+		if t.event == event && t.from.Has(current) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Transit 从current触发event，依次执行guard、离开动作、进入动作，成功后返回新状态，适合状态保存在数据库中的场景
+func (f *Fsm) Transit(current uint8, event string, data any) (next uint8, err error) {
+	fail := func(reason, cause error) error {
+		return &FsmError{Fsm: f.name, Event: event, From: current, Err: reason, Cause: cause}
+	}
+
+	// guard和action在锁外执行，其中可以调用Fsm的方法
+	f.mutex.RLock()
+	declared := f.states.Has(current)
+	transitions := f.transitions
+	f.mutex.RUnlock()
+
+	if !declared {
+		return current, fail(ErrFsmState, nil)
+	}
+
+	var (
+		matched    bool
+		transition *FsmTransition
+	)
+
+	for _, t := range transitions {
+		if t.event != event || !t.from.Has(current) {
+			continue
+		}
+
+		matched = true
+		candidate := &FsmTransition{
+			Fsm:   f.name,
+			Event: event,
+			From:  current,
+			To:    t.to,
+			Data:  data,
+		}
+
+		if f.allow(t.guards, candidate) {
+			transition = candidate
+			break
+		}
+	}
+
+	if !matched {
+		return current, fail(ErrFsmTransition, nil)
+	}
+
+	if transition == nil {
+		return current, fail(ErrFsmGuard, nil)
+	}
+
+	f.mutex.RLock()
+	exit, enter := f.exit[current], f.enter[transition.To]
+	f.mutex.RUnlock()
+
+	for _, action := range exit {
+		if err = action(transition); err != nil {
+			return current, fail(ErrFsmAction, err)
+		}
+	}
+
+	for _, action := range enter {
+		if err = action(transition); err != nil {
+			return current, fail(ErrFsmAction, err)
+		}
+	}
+
+	if f.events != nil {
+		f.events.Trigger(EventFsmTransition, transition)
+	}
+
+	return transition.To, nil
+}
+
+func (f *Fsm) allow(guards []FsmGuard, t *FsmTransition) bool {
+	for _, guard := range guards {
+		if !guard(t) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewMachine 从初始状态创建状态机实例
+func (f *Fsm) NewMachine() *FsmMachine {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return &FsmMachine{
+		fsm:     f,
+		current: f.initial,
+	}
+}
+
+// Restore 从已保存的状态恢复状态机实例
+func (f *Fsm) Restore(current uint8) (*FsmMachine, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	if !f.states.Has(current) {
+		return nil, ErrFsmState
+	}
+
+	return &FsmMachine{
+		fsm:     f,
+		current: current,
+	}, nil
+}
+
+// FsmMachine 状态机实例，例如一个订单或工单，并发安全
+type FsmMachine struct {
+	mutex   sync.Mutex
+	fsm     *Fsm
+	current uint8
+}
+
+// Current 当前状态
+func (fm *FsmMachine) Current() uint8 {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+
+	return fm.current
+}
+
+// Is 当前状态是否为state
+func (fm *FsmMachine) Is(state uint8) bool {
+	return fm.Current() == state
+}
+
+// Can 当前状态是否可以触发event
+func (fm *FsmMachine) Can(event string) bool {
+	return fm.fsm.Can(fm.Current(), event)
+}
+
+// Fire 触发event，失败时状态不变
+func (fm *FsmMachine) Fire(event string, data any) error {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+
+	next, err := fm.fsm.Transit(fm.current, event, data)
+	if err != nil {
+		return err
+	}
+
+	fm.current = next
+	return nil
+}
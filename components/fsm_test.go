@@ -0,0 +1,157 @@
+package components
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const (
+	orderCreated uint8 = iota
+	orderPaid
+	orderShipped
+	orderDone
+	orderCanceled
+)
+
+func newOrderFsm(t *testing.T, em *EventManager) *Fsm {
+	f := NewFsm("order", em)
+	for state, name := range []string{"created", "paid", "shipped", "done", "canceled"} {
+		if err := f.AddState(uint8(state), name); err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+	}
+
+	_ = f.AddTransition("pay", []uint8{orderCreated}, orderPaid, func(tr *FsmTransition) bool {
+		amount, _ := tr.Data.(int)
+		return amount > 0
+	})
+	_ = f.AddTransition("ship", []uint8{orderPaid}, orderShipped)
+	_ = f.AddTransition("confirm", []uint8{orderShipped}, orderDone)
+	_ = f.AddTransition("cancel", []uint8{orderCreated, orderPaid}, orderCanceled)
+	return f
+}
+
+func TestFsm_Fire(t *testing.T) {
+	var (
+		em      = &EventManager{}
+		changes []*FsmTransition
+		trace   []string
+	)
+
+	em.On(EventFsmTransition, func(ctx *Context) {
+		changes = append(changes, ctx.Event().Data().(*FsmTransition))
+	})
+
+	f := newOrderFsm(t, em)
+	f.OnExit(orderCreated, func(tr *FsmTransition) error {
+		trace = append(trace, "exit:"+f.StateName(tr.From))
+		return nil
+	})
+	f.OnEnter(orderPaid, func(tr *FsmTransition) error {
+		trace = append(trace, "enter:"+f.StateName(tr.To))
+		return nil
+	})
+
+	m := f.NewMachine()
+	if !m.Is(orderCreated) || !m.Can("pay") || m.Can("ship") {
+		t.Fatalf("want created, got %d", m.Current())
+	}
+
+	err := m.Fire("ship", nil)
+	if !errors.Is(err, ErrFsmTransition) {
+		t.Fatalf("want ErrFsmTransition, got %v", err)
+	}
+
+	var fe *FsmError
+	if !errors.As(err, &fe) || fe.Event != "ship" || fe.From != orderCreated {
+		t.Fatalf("want FsmError, got %v", err)
+	}
+
+	if err = m.Fire("pay", 0); !errors.Is(err, ErrFsmGuard) || !m.Is(orderCreated) {
+		t.Fatalf("want ErrFsmGuard, got %v", err)
+	}
+
+	if err = m.Fire("pay", 100); err != nil || !m.Is(orderPaid) {
+		t.Fatalf("want paid, got %d %v", m.Current(), err)
+	}
+
+	if strings.Join(trace, ",") != "exit:created,enter:paid" {
+		t.Fatalf("want exit and enter, got %v", trace)
+	}
+
+	if len(changes) != 1 || changes[0].From != orderCreated || changes[0].To != orderPaid || changes[0].Event != "pay" {
+		t.Fatalf("want one change event, got %+v", changes)
+	}
+
+	if events := f.Events(orderPaid); strings.Join(events, ",") != "ship,cancel" {
+		t.Fatalf("want ship,cancel, got %v", events)
+	}
+}
+
+func TestFsm_AddState(t *testing.T) {
+	f := NewFsm("invalid", nil)
+
+	// 添加失败的状态不能成为初始状态
+	if err := f.AddState(31, "overflow"); err == nil {
+		t.Fatal("want error, got nil")
+	}
+
+	if m := f.NewMachine(); m.Is(31) {
+		t.Fatal("want unregistered state not initial")
+	}
+
+	_ = f.AddState(2, "first")
+	_ = f.AddState(3, "second")
+	if m := f.NewMachine(); !m.Is(2) {
+		t.Fatalf("want 2, got %d", m.Current())
+	}
+}
+
+func TestFsm_Action(t *testing.T) {
+	f := newOrderFsm(t, nil)
+
+	refused := errors.New("warehouse closed")
+	f.OnEnter(orderShipped, func(tr *FsmTransition) error {
+		return refused
+	})
+
+	m, err := f.Restore(orderPaid)
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if err = m.Fire("ship", nil); !errors.Is(err, ErrFsmAction) || !errors.Is(err, refused) || !m.Is(orderPaid) {
+		t.Fatalf("want ErrFsmAction, got %v %d", err, m.Current())
+	}
+
+	if next, err := f.Transit(orderPaid, "cancel", nil); err != nil || next != orderCanceled {
+		t.Fatalf("want canceled, got %d %v", next, err)
+	}
+
+	if _, err = f.Restore(20); err != ErrFsmState {
+		t.Fatalf("want ErrFsmState, got %v", err)
+	}
+
+	if err = f.AddTransition("reopen", []uint8{orderDone}, 21); err != ErrFsmState {
+		t.Fatalf("want ErrFsmState, got %v", err)
+	}
+}
+
+func TestFsm_Export(t *testing.T) {
+	f := newOrderFsm(t, nil)
+
+	dot := f.Graphviz()
+	for _, want := range []string{`digraph "order" {`, `s0 [label="created", shape=doublecircle];`, `s0 -> s1 [label="pay"];`, `s1 -> s4 [label="cancel"];`} {
+		if !strings.Contains(dot, want) {
+			t.Fatalf("want %s, got %s", want, dot)
+		}
+	}
+
+	mermaid := f.Mermaid()
+	for _, want := range []string{"stateDiagram-v2\n", "s3 : done", "[*] --> s0", "s2 --> s3 : confirm"} {
+		if !strings.Contains(mermaid, want) {
+			t.Fatalf("want %s, got %s", want, mermaid)
+		}
+	}
+}
@@ -11,7 +11,7 @@ import (
 )
 
 var (
-	hashGroup = &Group{}
+	hashGroup = &ringGroup{}
 	hostList  = []string{
 		"192.168.1.135:3551:v0",
 		"192.168.1.135:3551:v1",
@@ -71,7 +71,7 @@ func (d *Data) HashCode() (hashValue uint32) {
 	return kind.Uint32Hash(utils.String2Bytes(d.id))
 }
 
-type Group struct {
+type ringGroup struct {
 	ring HashRing
 }
 
@@ -0,0 +1,145 @@
+package components
+
+import (
+	"strings"
+	"sync"
+)
+
+// CLDR复数类别
+const (
+	PluralZero  = `zero`
+	PluralOne   = `one`
+	PluralTwo   = `two`
+	PluralFew   = `few`
+	PluralMany  = `many`
+	PluralOther = `other`
+)
+
+// PluralRule 根据数量返回CLDR复数类别
+type PluralRule func(n int64) (category string)
+
+var (
+	pluralMutex sync.RWMutex
+	pluralRules = map[string]PluralRule{}
+)
+
+func init() {
+	registerPluralRule(pluralOther, "zh", "ja", "ko", "vi", "th", "id", "ms", "lo", "my")
+	registerPluralRule(pluralOneOther, "en", "de", "nl", "sv", "da", "no", "nb", "fi", "et", "it", "es", "pt", "el", "hu", "tr", "bg")
+	registerPluralRule(pluralFrench, "fr")
+	registerPluralRule(pluralSlavic, "ru", "uk", "be")
+	registerPluralRule(pluralPolish, "pl")
+	registerPluralRule(pluralCzech, "cs", "sk")
+	registerPluralRule(pluralArabic, "ar")
+}
+
+// RegisterPluralRule 注册语言的复数规则，language可以是主语言(en)或带地区的语言(pt_BR)
+func RegisterPluralRule(language string, rule PluralRule) {
+	registerPluralRule(rule, language)
+}
+
+// PluralCategory 获取language下数量n对应的复数类别，未注册的语言返回PluralOther
+func PluralCategory(language string, n int64) string {
+	if n < 0 {
+		n = -n
+	}
+
+	language = NormalizeLang(language)
+
+	pluralMutex.RLock()
+	rule, exists := pluralRules[language]
+	if !exists {
+		if index := strings.IndexByte(language, '_'); index > 0 {
+			rule, exists = pluralRules[language[:index]]
+		}
+	}
+	pluralMutex.RUnlock()
+
+	if !exists {
+		return PluralOther
+	}
+
+	return rule(n)
+}
+
+func registerPluralRule(rule PluralRule, languages ...string) {
+	pluralMutex.Lock()
+	defer pluralMutex.Unlock()
+
+	for _, lang := range languages {
+		pluralRules[NormalizeLang(lang)] = rule
+	}
+}
+
+func pluralOther(n int64) string {
+	return PluralOther
+}
+
+func pluralOneOther(n int64) string {
+	if n == 1 {
+		return PluralOne
+	}
+
+	return PluralOther
+}
+
+func pluralFrench(n int64) string {
+	if n == 0 || n == 1 {
+		return PluralOne
+	}
+
+	return PluralOther
+}
+
+func pluralSlavic(n int64) string {
+	mod10, mod100 := n%10, n%100
+	switch {
+	case mod10 == 1 && mod100 != 11:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func pluralPolish(n int64) string {
+	mod10, mod100 := n%10, n%100
+	switch {
+	case n == 1:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func pluralCzech(n int64) string {
+	switch {
+	case n == 1:
+		return PluralOne
+	case n >= 2 && n <= 4:
+		return PluralFew
+	default:
+		return PluralOther
+	}
+}
+
+func pluralArabic(n int64) string {
+	mod100 := n % 100
+	switch {
+	case n == 0:
+		return PluralZero
+	case n == 1:
+		return PluralOne
+	case n == 2:
+		return PluralTwo
+	case mod100 >= 3 && mod100 <= 10:
+		return PluralFew
+	case mod100 >= 11:
+		return PluralMany
+	default:
+		return PluralOther
+	}
+}
@@ -0,0 +1,221 @@
+package components
+
+import (
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grpc-boot/base/v3/logger"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+// I18nLoader 加载多语言消息
+type I18nLoader func() (msgMap map[string]map[string]string, err error)
+
+// YamlLoader 从目录加载Yaml消息文件
+func YamlLoader(dir string) I18nLoader {
+	return YamlFSLoader(os.DirFS(dirOrCurrent(dir)), ".")
+}
+
+// YamlFSLoader 从fs.FS加载Yaml消息文件
+func YamlFSLoader(fsys fs.FS, dir string) I18nLoader {
+	return func() (map[string]map[string]string, error) {
+		return LoadYamlMsgMap(fsys, dir)
+	}
+}
+
+// JsonLoader 从目录加载Json消息文件
+func JsonLoader(dir string) I18nLoader {
+	return JsonFSLoader(os.DirFS(dirOrCurrent(dir)), ".")
+}
+
+// JsonFSLoader 从fs.FS加载Json消息文件
+func JsonFSLoader(fsys fs.FS, dir string) I18nLoader {
+	return func() (map[string]map[string]string, error) {
+		return LoadJsonMsgMap(fsys, dir)
+	}
+}
+
+// ReloadableI18n 可热加载的多语言，重新加载时原子替换消息，读取不加锁
+type ReloadableI18n struct {
+	loader    I18nLoader
+	current   atomic.Pointer[i18n]
+	mutex     sync.Mutex
+	fallbacks map[string][]string
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewReloadableI18n 实例化可热加载的多语言
+func NewReloadableI18n(loader I18nLoader) (*ReloadableI18n, error) {
+	r := &ReloadableI18n{
+		loader:    loader,
+		fallbacks: make(map[string][]string),
+		done:      make(chan struct{}),
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload 重新加载消息，加载失败时保留原消息
+func (r *ReloadableI18n) Reload() error {
+	msgMap, err := r.loader()
+	if err != nil {
+		return err
+	}
+
+	if len(msgMap) < 1 {
+		return ErrMsgMapEmpty
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	fallbacks := make(map[string][]string, len(r.fallbacks))
+	for lang, chain := range r.fallbacks {
+		fallbacks[lang] = chain
+	}
+
+	r.current.Store(&i18n{
+		msgMap:    msgMap,
+		fallbacks: fallbacks,
+	})
+
+	return nil
+}
+
+// Watch 每隔interval检查dir目录下文件的大小和修改时间，有变化时重新加载，调用Close停止
+func (r *ReloadableI18n) Watch(dir string, interval time.Duration) {
+	dir = dirOrCurrent(dir)
+	last, _ := dirSignature(dir)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.done:
+				return
+			case <-ticker.C:
+			}
+
+			sign, err := dirSignature(dir)
+			if err != nil {
+				logger.Error("i18n watch dir failed",
+					zap.String("Dir", dir),
+					zap.NamedError("Error", err),
+				)
+				continue
+			}
+
+			if sign == last {
+				continue
+			}
+
+			if err = r.Reload(); err != nil {
+				logger.Error("i18n reload failed",
+					zap.String("Dir", dir),
+					zap.NamedError("Error", err),
+				)
+				continue
+			}
+
+			last = sign
+		}
+	}()
+}
+
+// Close 停止Watch
+func (r *ReloadableI18n) Close() {
+	r.closeOnce.Do(func() {
+		close(r.done)
+	})
+}
+
+func (r *ReloadableI18n) T(key string) string {
+	return r.current.Load().T(key)
+}
+
+func (r *ReloadableI18n) Tl(key, language string) string {
+	return r.current.Load().Tl(key, language)
+}
+
+func (r *ReloadableI18n) Tf(key string, args Args) string {
+	return r.current.Load().Tf(key, args)
+}
+
+func (r *ReloadableI18n) Tlf(key, language string, args Args) string {
+	return r.current.Load().Tlf(key, language, args)
+}
+
+func (r *ReloadableI18n) Tn(key string, count int64, args Args) string {
+	return r.current.Load().Tn(key, count, args)
+}
+
+func (r *ReloadableI18n) Tln(key, language string, count int64, args Args) string {
+	return r.current.Load().Tln(key, language, count, args)
+}
+
+func (r *ReloadableI18n) SetFallback(language string, fallbacks ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	language = NormalizeLang(language)
+	if len(fallbacks) < 1 {
+		delete(r.fallbacks, language)
+	} else {
+		chain := make([]string, len(fallbacks))
+		for index, lang := range fallbacks {
+			chain[index] = NormalizeLang(lang)
+		}
+		r.fallbacks[language] = chain
+	}
+
+	r.current.Load().SetFallback(language, fallbacks...)
+}
+
+func (r *ReloadableI18n) Languages() []string {
+	return r.current.Load().Languages()
+}
+
+func (r *ReloadableI18n) Negotiate(acceptLanguage string) string {
+	return r.current.Load().Negotiate(acceptLanguage)
+}
+
+func (r *ReloadableI18n) MissingKeys() map[string][]string {
+	return r.current.Load().MissingKeys()
+}
+
+func dirSignature(dir string) (string, error) {
+	fileList, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	for _, fi := range fileList {
+		info, err := fi.Info()
+		if err != nil {
+			return "", err
+		}
+
+		buf.WriteString(fi.Name())
+		buf.WriteByte(':')
+		buf.WriteString(strconv.FormatInt(info.Size(), 10))
+		buf.WriteByte(':')
+		buf.WriteString(strconv.FormatInt(info.ModTime().UnixNano(), 10))
+		buf.WriteByte('\n')
+	}
+
+	return buf.String(), nil
+}
@@ -2,8 +2,14 @@ package components
 
 import (
 	"errors"
+	"io/fs"
 	"os"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/grpc-boot/base/v3/utils"
 )
@@ -13,6 +19,10 @@ const (
 	En   = `en`
 )
 
+const (
+	countArg = `count`
+)
+
 var (
 	DefaultLang = ZhCn
 )
@@ -23,10 +33,31 @@ var (
 	ErrNoJsonFiles = errors.New("there are no `.json` files in this directory")
 )
 
+// Args 占位符参数，{name}会被替换为Args["name"]
+type Args map[string]any
+
 // I18n 多语言接口
 type I18n interface {
+	// T 使用默认语言翻译
 	T(key string) (msg string)
+	// Tl 使用指定语言翻译
 	Tl(key, language string) (msg string)
+	// Tf 使用默认语言翻译，并替换{name}占位符
+	Tf(key string, args Args) (msg string)
+	// Tlf 使用指定语言翻译，并替换{name}占位符
+	Tlf(key, language string, args Args) (msg string)
+	// Tn 使用默认语言按count选择复数形式翻译，{count}占位符会被替换为count
+	Tn(key string, count int64, args Args) (msg string)
+	// Tln 使用指定语言按count选择复数形式翻译，{count}占位符会被替换为count
+	Tln(key, language string, count int64, args Args) (msg string)
+	// SetFallback 设置语言的回退链，如zh_HK -> zh_CN -> en
+	SetFallback(language string, fallbacks ...string)
+	// Languages 已加载的语言列表
+	Languages() []string
+	// Negotiate 根据Accept-Language请求头选择已加载的语言，没有匹配时返回DefaultLang
+	Negotiate(acceptLanguage string) (language string)
+	// MissingKeys 按语言返回其他语言中存在而该语言缺失的key，复数形式(key.one、key.other等)按key统计
+	MissingKeys() (missing map[string][]string)
 }
 
 // NewI18n 实例化多语言
@@ -36,91 +67,152 @@ func NewI18n(msgMap map[string]map[string]string) (i I18n, err error) {
 	}
 
 	i = &i18n{
-		msgMap: msgMap,
+		msgMap:    msgMap,
+		fallbacks: make(map[string][]string),
 	}
 
 	return i, nil
 }
 
-// NewI18nFromYaml 从Yaml文件配置实例化多语言
+// NewI18nFromYaml 从目录下的Yaml文件配置实例化多语言，文件名为语言，支持嵌套结构
 func NewI18nFromYaml(dir string) (i I18n, err error) {
-	fileList, err := os.ReadDir(dir)
+	return NewI18nFromYamlFS(os.DirFS(dirOrCurrent(dir)), ".")
+}
+
+// NewI18nFromYamlFS 从fs.FS(如embed.FS)中dir目录下的Yaml文件配置实例化多语言
+func NewI18nFromYamlFS(fsys fs.FS, dir string) (i I18n, err error) {
+	msgMap, err := LoadYamlMsgMap(fsys, dir)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(fileList) < 1 {
-		return nil, ErrNoYamlFiles
-	}
-
-	var msgMap = make(map[string]map[string]string, len(fileList))
-	for _, fi := range fileList {
-		fileName := strings.SplitN(fi.Name(), ".", 2)
-		if len(fileName) != 2 {
-			continue
-		}
-
-		if fileName[1] != "yml" && fileName[1] != "yaml" {
-			continue
-		}
-
-		var msg map[string]string
-		err = utils.YamlUnmarshalFile(dir+fi.Name(), &msg)
-		if err != nil {
-			return nil, err
-		}
+	return NewI18n(msgMap)
+}
 
-		msgMap[fileName[0]] = msg
-	}
+// NewI18nFromJson 从目录下的Json文件配置实例化多语言，文件名为语言，支持嵌套结构
+func NewI18nFromJson(dir string) (i I18n, err error) {
+	return NewI18nFromJsonFS(os.DirFS(dirOrCurrent(dir)), ".")
+}
 
-	if len(msgMap) < 1 {
-		return nil, ErrNoYamlFiles
+// NewI18nFromJsonFS 从fs.FS(如embed.FS)中dir目录下的Json文件配置实例化多语言
+func NewI18nFromJsonFS(fsys fs.FS, dir string) (i I18n, err error) {
+	msgMap, err := LoadJsonMsgMap(fsys, dir)
+	if err != nil {
+		return nil, err
 	}
 
 	return NewI18n(msgMap)
 }
 
-// NewI18nFromJson 从Json文件配置实例化多语言
-func NewI18nFromJson(dir string) (i I18n, err error) {
-	fileList, err := os.ReadDir(dir)
+// LoadYamlMsgMap 加载fs.FS中dir目录下的Yaml文件，嵌套结构会被展开为以.连接的key
+func LoadYamlMsgMap(fsys fs.FS, dir string) (msgMap map[string]map[string]string, err error) {
+	return loadMsgMap(fsys, dir, utils.YamlUnmarshal, ErrNoYamlFiles, "yml", "yaml")
+}
+
+// LoadJsonMsgMap 加载fs.FS中dir目录下的Json文件，嵌套结构会被展开为以.连接的key
+func LoadJsonMsgMap(fsys fs.FS, dir string) (msgMap map[string]map[string]string, err error) {
+	return loadMsgMap(fsys, dir, utils.JsonUnmarshal, ErrNoJsonFiles, "json")
+}
+
+func loadMsgMap(fsys fs.FS, dir string, unmarshal func(data []byte, v any) error, errNoFiles error, exts ...string) (msgMap map[string]map[string]string, err error) {
+	dir = dirOrCurrent(dir)
+
+	fileList, err := fs.ReadDir(fsys, dir)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(fileList) < 1 {
-		return nil, ErrNoJsonFiles
+		return nil, errNoFiles
 	}
 
-	var msgMap = make(map[string]map[string]string, len(fileList))
+	msgMap = make(map[string]map[string]string, len(fileList))
 	for _, fi := range fileList {
+		if fi.IsDir() {
+			continue
+		}
+
 		fileName := strings.SplitN(fi.Name(), ".", 2)
 		if len(fileName) != 2 {
 			continue
 		}
 
-		if fileName[1] != "json" {
+		if !slices.Contains(exts, fileName[1]) {
 			continue
 		}
 
-		var msg map[string]string
-		err = utils.JsonUnmarshalFile(dir+fi.Name(), &msg)
+		data, err := fs.ReadFile(fsys, path.Join(dir, fi.Name()))
 		if err != nil {
 			return nil, err
 		}
 
-		msgMap[fileName[0]] = msg
+		var msg map[string]any
+		if err = unmarshal(data, &msg); err != nil {
+			return nil, err
+		}
+
+		msgMap[fileName[0]] = FlattenMsg(msg)
 	}
 
 	if len(msgMap) < 1 {
-		return nil, ErrNoJsonFiles
+		return nil, errNoFiles
 	}
-	return NewI18n(msgMap)
+
+	return msgMap, nil
+}
+
+// FlattenMsg 将嵌套的消息展开为以.连接的key，如{"user": {"name": "x"}}展开为{"user.name": "x"}
+func FlattenMsg(msg map[string]any) map[string]string {
+	flat := make(map[string]string, len(msg))
+	flattenMsg(flat, "", msg)
+	return flat
+}
+
+func flattenMsg(flat map[string]string, prefix string, value any) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case map[string]any:
+		for key, val := range v {
+			flattenMsg(flat, joinMsgKey(prefix, key), val)
+		}
+	case map[any]any:
+		for key, val := range v {
+			flattenMsg(flat, joinMsgKey(prefix, utils.ToString(key)), val)
+		}
+	case []any:
+		for index, val := range v {
+			flattenMsg(flat, joinMsgKey(prefix, strconv.Itoa(index)), val)
+		}
+	default:
+		if prefix != "" {
+			flat[prefix] = utils.ToString(v)
+		}
+	}
+}
+
+func joinMsgKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+func dirOrCurrent(dir string) string {
+	if dir == "" {
+		return "."
+	}
+
+	return dir
 }
 
 type i18n struct {
 	I18n
 
-	msgMap map[string]map[string]string
+	msgMap    map[string]map[string]string
+	mutex     sync.RWMutex
+	fallbacks map[string][]string
 }
 
 func (i *i18n) T(key string) string {
@@ -128,13 +220,333 @@ func (i *i18n) T(key string) string {
 }
 
 func (i *i18n) Tl(key, language string) string {
-	if _, exists := i.msgMap[language]; !exists {
+	if msg, ok := i.lookup(key, language); ok {
+		return msg
+	}
+
+	return key
+}
+
+func (i *i18n) Tf(key string, args Args) string {
+	return i.Tlf(key, DefaultLang, args)
+}
+
+func (i *i18n) Tlf(key, language string, args Args) string {
+	return Interpolate(i.Tl(key, language), args)
+}
+
+func (i *i18n) Tn(key string, count int64, args Args) string {
+	return i.Tln(key, DefaultLang, count, args)
+}
+
+func (i *i18n) Tln(key, language string, count int64, args Args) string {
+	var (
+		msg string
+		ok  bool
+	)
+
+	for _, lang := range i.chain(language) {
+		category := PluralCategory(lang, count)
+		if msg, ok = i.msgMap[lang][key+"."+category]; ok {
+			break
+		}
+
+		if category != PluralOther {
+			if msg, ok = i.msgMap[lang][key+"."+PluralOther]; ok {
+				break
+			}
+		}
+
+		if msg, ok = i.msgMap[lang][key]; ok {
+			break
+		}
+	}
+
+	if !ok {
+		msg = key
+	}
+
+	if _, exists := args[countArg]; !exists {
+		withCount := make(Args, len(args)+1)
+		for k, v := range args {
+			withCount[k] = v
+		}
+		withCount[countArg] = count
+		args = withCount
+	}
+
+	return Interpolate(msg, args)
+}
+
+func (i *i18n) SetFallback(language string, fallbacks ...string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	language = NormalizeLang(language)
+	if len(fallbacks) < 1 {
+		delete(i.fallbacks, language)
+		return
+	}
+
+	chain := make([]string, len(fallbacks))
+	for index, lang := range fallbacks {
+		chain[index] = NormalizeLang(lang)
+	}
+
+	i.fallbacks[language] = chain
+}
+
+func (i *i18n) Languages() []string {
+	list := make([]string, 0, len(i.msgMap))
+	for lang := range i.msgMap {
+		list = append(list, lang)
+	}
+
+	sort.Strings(list)
+	return list
+}
+
+func (i *i18n) Negotiate(acceptLanguage string) string {
+	for _, tag := range ParseAcceptLanguage(acceptLanguage) {
+		if tag == "*" {
+			break
+		}
+
+		if lang, ok := i.match(tag); ok {
+			return lang
+		}
+	}
+
+	return DefaultLang
+}
+
+func (i *i18n) MissingKeys() map[string][]string {
+	var (
+		all     = make(map[string]struct{})
+		langKey = make(map[string]map[string]struct{}, len(i.msgMap))
+	)
+
+	for lang, msg := range i.msgMap {
+		keys := make(map[string]struct{}, len(msg))
+		for key := range msg {
+			key = pluralBaseKey(key)
+			keys[key] = struct{}{}
+			all[key] = struct{}{}
+		}
+		langKey[lang] = keys
+	}
+
+	missing := make(map[string][]string)
+	for lang, keys := range langKey {
+		for key := range all {
+			if _, exists := keys[key]; !exists {
+				missing[lang] = append(missing[lang], key)
+			}
+		}
+
+		if len(missing[lang]) > 0 {
+			sort.Strings(missing[lang])
+		}
+	}
+
+	return missing
+}
+
+func (i *i18n) lookup(key, language string) (msg string, ok bool) {
+	for _, lang := range i.chain(language) {
+		if msg, ok = i.msgMap[lang][key]; ok {
+			return
+		}
+	}
+
+	return
+}
+
+// chain 语言查找顺序：自身、回退链(可传递)、DefaultLang
+func (i *i18n) chain(language string) []string {
+	language = NormalizeLang(language)
+
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	if len(i.fallbacks) == 0 {
+		if language == DefaultLang {
+			return []string{language}
+		}
+
+		return []string{language, DefaultLang}
+	}
+
+	var (
+		list    = make([]string, 0, 4)
+		visited = make(map[string]struct{}, 4)
+		queue   = []string{language}
+	)
+
+	for len(queue) > 0 {
+		lang := queue[0]
+		queue = queue[1:]
+
+		if _, exists := visited[lang]; exists {
+			continue
+		}
+
+		visited[lang] = struct{}{}
+		list = append(list, lang)
+		queue = append(queue, i.fallbacks[lang]...)
+	}
+
+	if _, exists := visited[DefaultLang]; !exists {
+		list = append(list, DefaultLang)
+	}
+
+	return list
+}
+
+// match 匹配语言标签：完全匹配、去掉地区后匹配、同一主语言的第一个语言
+func (i *i18n) match(tag string) (language string, ok bool) {
+	tag = NormalizeLang(tag)
+
+	for lang := range i.msgMap {
+		if strings.EqualFold(lang, tag) {
+			return lang, true
+		}
+	}
+
+	base := tag
+	if index := strings.IndexByte(tag, '_'); index > 0 {
+		base = tag[:index]
+	}
+
+	for _, lang := range i.Languages() {
+		if strings.EqualFold(lang, base) {
+			return lang, true
+		}
+	}
+
+	for _, lang := range i.Languages() {
+		if len(lang) > len(base) && lang[len(base)] == '_' && strings.EqualFold(lang[:len(base)], base) {
+			return lang, true
+		}
+	}
+
+	return
+}
+
+// pluralBaseKey 去掉复数类别后缀，apples.one返回apples
+func pluralBaseKey(key string) string {
+	index := strings.LastIndexByte(key, '.')
+	if index < 0 {
 		return key
 	}
 
-	if msg, ok := i.msgMap[language][key]; ok {
-		return msg
+	switch key[index+1:] {
+	case PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther:
+		return key[:index]
 	}
 
 	return key
 }
+
+// NormalizeLang 规范化语言标签，zh-CN转为zh_CN
+func NormalizeLang(language string) string {
+	return strings.ReplaceAll(strings.TrimSpace(language), "-", "_")
+}
+
+// ParseAcceptLanguage 解析Accept-Language请求头，按q值从高到低返回语言标签
+func ParseAcceptLanguage(acceptLanguage string) []string {
+	if acceptLanguage == "" {
+		return nil
+	}
+
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var (
+		parts = strings.Split(acceptLanguage, ",")
+		list  = make([]weighted, 0, len(parts))
+	)
+
+	for _, part := range parts {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+
+			value, err := strconv.ParseFloat(param[2:], 64)
+			if err != nil {
+				value = 0
+			}
+			q = value
+		}
+
+		if q <= 0 {
+			continue
+		}
+
+		list = append(list, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(list, func(a, b int) bool {
+		return list[a].q > list[b].q
+	})
+
+	tags := make([]string, len(list))
+	for index, w := range list {
+		tags[index] = w.tag
+	}
+
+	return tags
+}
+
+// Interpolate 使用args替换msg中的{name}占位符，未提供的占位符保持不变
+func Interpolate(msg string, args Args) string {
+	if len(args) == 0 || strings.IndexByte(msg, '{') < 0 {
+		return msg
+	}
+
+	var (
+		buf   strings.Builder
+		start int
+	)
+
+	buf.Grow(len(msg))
+
+	for start < len(msg) {
+		open := strings.IndexByte(msg[start:], '{')
+		if open < 0 {
+			break
+		}
+		open += start
+
+		end := strings.IndexByte(msg[open+1:], '}')
+		if end < 0 {
+			break
+		}
+		end += open + 1
+
+		value, exists := args[msg[open+1:end]]
+		if !exists {
+			buf.WriteString(msg[start : end+1])
+			start = end + 1
+			continue
+		}
+
+		buf.WriteString(msg[start:open])
+		buf.WriteString(utils.ToString(value))
+		start = end + 1
+	}
+
+	buf.WriteString(msg[start:])
+	return buf.String()
+}
@@ -1,6 +1,12 @@
 package components
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
 
 func TestI18n_T(t *testing.T) {
 	transl, err := NewI18nFromYaml("./")
@@ -13,3 +19,195 @@ func TestI18n_T(t *testing.T) {
 	t.Logf("en:%s zh_CN:%s", transl.Tl("Argument Error", En), transl.T("Argument Error"))
 	t.Logf("en:%s zh_CN:%s", transl.Tl("Internal Error", En), transl.T("Internal Error"))
 }
+
+func TestI18n_Tln(t *testing.T) {
+	transl, err := NewI18n(map[string]map[string]string{
+		ZhCn: {
+			"welcome":     "欢迎{name}",
+			"apples":      "{count}个苹果",
+			"only_zh":     "仅中文",
+			"unknown_arg": "你好{who}",
+		},
+		En: {
+			"welcome":      "Welcome {name}",
+			"apples.one":   "{count} apple",
+			"apples.other": "{count} apples",
+		},
+		"ru": {
+			"apples.one":  "{count} яблоко",
+			"apples.few":  "{count} яблока",
+			"apples.many": "{count} яблок",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	DefaultLang = ZhCn
+
+	msg := transl.Tlf("welcome", En, Args{"name": "Tom"})
+	if msg != "Welcome Tom" {
+		t.Fatalf("want Welcome Tom, got %s", msg)
+	}
+
+	msg = transl.Tf("unknown_arg", Args{"name": "Tom"})
+	if msg != "你好{who}" {
+		t.Fatalf("want 你好{who}, got %s", msg)
+	}
+
+	cases := []struct {
+		lang  string
+		count int64
+		want  string
+	}{
+		{En, 1, "1 apple"},
+		{En, 0, "0 apples"},
+		{En, 5, "5 apples"},
+		{"ru", 1, "1 яблоко"},
+		{"ru", 3, "3 яблока"},
+		{"ru", 12, "12 яблок"},
+		{"ru", 21, "21 яблоко"},
+		{ZhCn, 2, "2个苹果"},
+	}
+
+	for _, c := range cases {
+		msg = transl.Tln("apples", c.lang, c.count, nil)
+		if msg != c.want {
+			t.Fatalf("want %s, got %s", c.want, msg)
+		}
+	}
+
+	transl.SetFallback("zh-HK", ZhCn, En)
+	transl.SetFallback("fr", En)
+
+	msg = transl.Tl("only_zh", "zh_HK")
+	if msg != "仅中文" {
+		t.Fatalf("want 仅中文, got %s", msg)
+	}
+
+	msg = transl.Tlf("welcome", "fr", Args{"name": "Tom"})
+	if msg != "Welcome Tom" {
+		t.Fatalf("want Welcome Tom, got %s", msg)
+	}
+
+	msg = transl.Tl("missing", En)
+	if msg != "missing" {
+		t.Fatalf("want missing, got %s", msg)
+	}
+}
+
+func TestI18n_Negotiate(t *testing.T) {
+	transl, err := NewI18n(map[string]map[string]string{
+		ZhCn: {"ok": "好"},
+		En:   {"ok": "ok"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	DefaultLang = ZhCn
+
+	cases := map[string]string{
+		"":                           ZhCn,
+		"en-US,en;q=0.9":             En,
+		"fr-FR, zh;q=0.8, en;q=0.9":  En,
+		"zh-TW;q=0.7, de":            ZhCn,
+		"de, *;q=0.5":                ZhCn,
+		"en;q=0, zh-cn;q=0.2":        ZhCn,
+		"ja,en-GB;q=0.8,zh-CN;q=0.8": En,
+	}
+
+	for header, want := range cases {
+		if lang := transl.Negotiate(header); lang != want {
+			t.Fatalf("header %q want %s, got %s", header, want, lang)
+		}
+	}
+}
+
+func TestNewI18nFromYamlFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"msgs/zh_CN.yml": {Data: []byte("user:\n  not_found: '用户不存在'\n  apples: '{count}个苹果'\nok: '好'\n")},
+		"msgs/en.json":   {Data: []byte(`{"user": {"not_found": "user not found", "apples": {"one": "{count} apple", "other": "{count} apples"}}}`)},
+	}
+
+	transl, err := NewI18nFromYamlFS(fsys, "msgs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := transl.Tl("user.not_found", ZhCn)
+	if msg != "用户不存在" {
+		t.Fatalf("want 用户不存在, got %s", msg)
+	}
+
+	transl, err = NewI18nFromJsonFS(fsys, "msgs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg = transl.Tln("user.apples", En, 2, nil)
+	if msg != "2 apples" {
+		t.Fatalf("want 2 apples, got %s", msg)
+	}
+
+	msgMap, err := LoadYamlMsgMap(fsys, "msgs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsonMap, _ := LoadJsonMsgMap(fsys, "msgs")
+	msgMap[En] = jsonMap[En]
+
+	transl, _ = NewI18n(msgMap)
+	missing := transl.MissingKeys()
+	if len(missing[En]) != 1 || missing[En][0] != "ok" {
+		t.Fatalf("want [ok], got %v", missing[En])
+	}
+
+	if len(missing[ZhCn]) != 0 {
+		t.Fatalf("want [], got %v", missing[ZhCn])
+	}
+
+	if _, err = NewI18nFromJson("."); err != ErrNoJsonFiles {
+		t.Fatalf("want %v, got %v", ErrNoJsonFiles, err)
+	}
+}
+
+func TestReloadableI18n_Watch(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "zh_CN.yml")
+
+	if err := os.WriteFile(file, []byte("hello: '你好'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	transl, err := NewReloadableI18n(YamlLoader(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer transl.Close()
+
+	DefaultLang = ZhCn
+	transl.SetFallback("zh_HK", ZhCn)
+	transl.Watch(dir, time.Millisecond*10)
+
+	if msg := transl.T("hello"); msg != "你好" {
+		t.Fatalf("want 你好, got %s", msg)
+	}
+
+	if err = os.WriteFile(file, []byte("hello: '您好'\nbye: '再见'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second * 3)
+	for transl.T("hello") != "您好" {
+		if time.Now().After(deadline) {
+			t.Fatalf("want 您好, got %s", transl.T("hello"))
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if msg := transl.Tl("bye", "zh_HK"); msg != "再见" {
+		t.Fatalf("want 再见, got %s", msg)
+	}
+}
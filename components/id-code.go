@@ -1,6 +1,8 @@
 package components
 
 import (
+	"math/bits"
+
 	"github.com/grpc-boot/base/v3/utils"
 )
 
@@ -12,27 +14,119 @@ var (
 	DefaultIdCode, _ = NewIdCode(DefaultBase62, defaultSalt)
 )
 
+// IdCodeOption IdCode选项
+type IdCodeOption func(ic *IdCode)
+
+// WithCheckChar 在编码末尾追加一位Luhn mod N校验字符，可以检出单个字符错误和相邻字符交换
+func WithCheckChar() IdCodeOption {
+	return func(ic *IdCode) {
+		ic.check = true
+	}
+}
+
+// WithCodeLength Id2Code生成定长编码，使用cycle-walking保证密文落在[0, size^length)内
+func WithCodeLength(length int) IdCodeOption {
+	return func(ic *IdCode) {
+		ic.length = length
+	}
+}
+
+// WithCodeVersion 在编码前追加一位版本字符，用于轮换salt，version取值[0, 字符集长度)
+func WithCodeVersion(version int) IdCodeOption {
+	return func(ic *IdCode) {
+		ic.version = version
+	}
+}
+
+// WithHistorySalt 历史版本的salt，轮换salt后旧版本的编码仍可以解码
+func WithHistorySalt(version int, salt int64) IdCodeOption {
+	return func(ic *IdCode) {
+		ic.salts[version] = salt
+	}
+}
+
 type IdCode struct {
-	base62 Base62
-	salt   int64
+	base62  Base62
+	size    int
+	salt    int64
+	check   bool
+	length  int
+	max     uint64
+	version int
+	salts   map[int]int64
 }
 
-func NewIdCode(base62 Base62, salt int64) (*IdCode, error) {
+func NewIdCode(base62 Base62, salt int64, opts ...IdCodeOption) (*IdCode, error) {
 	ic := &IdCode{
-		base62: base62,
-		salt:   salt,
+		base62:  base62,
+		size:    base62Size(base62),
+		salt:    salt,
+		version: -1,
+		salts:   map[int]int64{},
+	}
+
+	for _, opt := range opts {
+		opt(ic)
+	}
+
+	if ic.length < 0 {
+		return nil, ErrOutOfRange
+	}
+
+	if ic.length > 0 {
+		ic.max = 1
+		for i := 0; i < ic.length; i++ {
+			hi, lo := bits.Mul64(ic.max, uint64(ic.size))
+			if hi > 0 {
+				return nil, ErrOutOfRange
+			}
+			ic.max = lo
+		}
+	}
+
+	if ic.version >= 0 {
+		ic.salts[ic.version] = salt
+	}
+
+	for version := range ic.salts {
+		if version < 0 || version >= ic.size {
+			return nil, ErrOutOfRange
+		}
 	}
 
 	return ic, nil
 }
 
-func NewIdCodeWithCharset(charset []byte, salt int64) (*IdCode, error) {
+func NewIdCodeWithCharset(charset []byte, salt int64, opts ...IdCodeOption) (*IdCode, error) {
 	b62, err := NewBase62(charset)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewIdCode(b62, salt)
+	return NewIdCode(b62, salt, opts...)
+}
+
+// MaxId Id2Code可编码的最大id，未设置WithCodeLength时为math.MaxUint64
+func (ic *IdCode) MaxId() uint64 {
+	if ic.length == 0 {
+		return 1<<64 - 1
+	}
+
+	return ic.max - 1
+}
+
+// Id2Code 生成WithCodeLength指定长度的编码，未设置时同Id2Code64
+func (ic *IdCode) Id2Code(id uint64) (code []byte, err error) {
+	if ic.length == 0 {
+		return ic.Id2Code64(id)
+	}
+
+	if id < 1 || id >= ic.max {
+		return nil, ErrOutOfRange
+	}
+
+	obfuscated := utils.FeistelCycleEncrypt(id, ic.max, uint64(ic.salt))
+	return ic.wrap(ic.base62.Encode(obfuscated, ic.length))
 }
 
 func (ic *IdCode) Id2Code64(id uint64) (code []byte, err error) {
@@ -41,7 +135,7 @@ func (ic *IdCode) Id2Code64(id uint64) (code []byte, err error) {
 	}
 
 	obfuscated := utils.FeistelEncrypt64(id, uint64(ic.salt))
-	return ic.base62.Encode(obfuscated, 12), nil
+	return ic.wrap(ic.base62.Encode(obfuscated, 12))
 }
 
 func (ic *IdCode) Id2Code32(id uint32) (code []byte, err error) {
@@ -50,7 +144,7 @@ func (ic *IdCode) Id2Code32(id uint32) (code []byte, err error) {
 	}
 
 	obfuscated := utils.FeistelEncrypt32(id, uint32(ic.salt))
-	return ic.base62.Encode(uint64(obfuscated), 6), nil
+	return ic.wrap(ic.base62.Encode(uint64(obfuscated), 6))
 }
 
 func (ic *IdCode) CodeString2Id(code string) (id uint64, err error) {
@@ -58,14 +152,102 @@ func (ic *IdCode) CodeString2Id(code string) (id uint64, err error) {
 }
 
 func (ic *IdCode) Code2Id(code []byte) (id uint64, err error) {
+	salt := ic.salt
+
+	if ic.check {
+		if len(code) < 2 {
+			return 0, ErrDataFormat
+		}
+
+		checkChar, err := ic.checkChar(code[:len(code)-1])
+		if err != nil {
+			return 0, err
+		}
+
+		if checkChar != code[len(code)-1] {
+			return 0, ErrCheckChar
+		}
+
+		code = code[:len(code)-1]
+	}
+
+	if ic.version >= 0 {
+		if len(code) < 2 {
+			return 0, ErrDataFormat
+		}
+
+		version, err := ic.base62.Decode(code[:1])
+		if err != nil {
+			return 0, err
+		}
+
+		var exists bool
+		if salt, exists = ic.salts[int(version)]; !exists {
+			return 0, ErrCodeVersion
+		}
+
+		code = code[1:]
+	}
+
 	num, err := ic.base62.Decode(code)
 	if err != nil {
 		return 0, err
 	}
 
-	if len(code) == 12 {
-		return utils.FeistelDecrypt64(num, uint64(ic.salt)), nil
+	switch {
+	case ic.length > 0 && len(code) == ic.length:
+		if num >= ic.max {
+			return 0, ErrDataFormat
+		}
+		return utils.FeistelCycleDecrypt(num, ic.max, uint64(salt)), nil
+	case len(code) == 12:
+		return utils.FeistelDecrypt64(num, uint64(salt)), nil
+	}
+
+	return uint64(utils.FeistelDecrypt32(uint32(num), uint32(salt))), nil
+}
+
+// wrap 添加版本前缀和校验字符
+func (ic *IdCode) wrap(body []byte) ([]byte, error) {
+	if ic.version < 0 && !ic.check {
+		return body, nil
+	}
+
+	code := make([]byte, 0, len(body)+2)
+	if ic.version >= 0 {
+		code = append(code, ic.base62.Encode(uint64(ic.version), 1)...)
+	}
+	code = append(code, body...)
+
+	if ic.check {
+		checkChar, err := ic.checkChar(code)
+		if err != nil {
+			return nil, err
+		}
+		code = append(code, checkChar)
+	}
+
+	return code, nil
+}
+
+// checkChar Luhn mod N算法计算校验字符
+func (ic *IdCode) checkChar(code []byte) (byte, error) {
+	var (
+		n      = uint64(ic.size)
+		factor = uint64(2)
+		sum    uint64
+	)
+
+	for i := len(code) - 1; i >= 0; i-- {
+		value, err := ic.base62.Decode(code[i : i+1])
+		if err != nil {
+			return 0, err
+		}
+
+		addend := factor * value
+		factor = 3 - factor
+		sum += addend/n + addend%n
 	}
 
-	return uint64(utils.FeistelDecrypt32(uint32(num), uint32(ic.salt))), nil
+	return ic.base62.Encode((n-sum%n)%n, 1)[0], nil
 }
@@ -1,6 +1,8 @@
 package components
 
 import (
+	"bytes"
+
 	"golang.org/x/exp/rand"
 	"testing"
 	"time"
@@ -42,6 +44,124 @@ func TestCode12(t *testing.T) {
 	}
 }
 
+func TestIdCodeCheckChar(t *testing.T) {
+	ic, err := NewIdCode(DefaultBase62, defaultSalt, WithCheckChar())
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	code, _ := ic.Id2Code64(123456)
+	if len(code) != 13 {
+		t.Fatalf("want 13, got %d", len(code))
+	}
+
+	id, err := ic.Code2Id(code)
+	if err != nil || id != 123456 {
+		t.Fatalf("want 123456, got %d %v", id, err)
+	}
+
+	// 单个字符错误
+	for i := 0; i < len(code)-1; i++ {
+		wrong := append([]byte{}, code...)
+		wrong[i] = DefaultBase62Charset[(bytes.IndexByte(DefaultBase62Charset, wrong[i])+1)%len(DefaultBase62Charset)]
+		if _, err = ic.Code2Id(wrong); err != ErrCheckChar {
+			t.Fatalf("want ErrCheckChar, got %v", err)
+		}
+	}
+
+	// 相邻字符交换
+	for i := 0; i < len(code)-2; i++ {
+		if code[i] == code[i+1] {
+			continue
+		}
+
+		wrong := append([]byte{}, code...)
+		wrong[i], wrong[i+1] = wrong[i+1], wrong[i]
+		if _, err = ic.Code2Id(wrong); err != ErrCheckChar {
+			t.Fatalf("want ErrCheckChar, got %v", err)
+		}
+	}
+}
+
+func TestIdCodeLength(t *testing.T) {
+	_, err := NewIdCode(DefaultBase62, defaultSalt, WithCodeLength(11))
+	if err != ErrOutOfRange {
+		t.Fatalf("want ErrOutOfRange, got %v", err)
+	}
+
+	for _, length := range []int{2, 4, 8, 10} {
+		ic, err := NewIdCode(DefaultBase62, defaultSalt, WithCodeLength(length), WithCheckChar())
+		if err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+
+		ids := []uint64{1, 2, 3, ic.MaxId()}
+		for i := 0; i < 100; i++ {
+			ids = append(ids, rand.Uint64()%ic.MaxId()+1)
+		}
+
+		for _, id := range ids {
+			code, err := ic.Id2Code(id)
+			if err != nil {
+				t.Fatalf("want nil, got %v", err)
+			}
+
+			if len(code) != length+1 {
+				t.Fatalf("want %d, got %d", length+1, len(code))
+			}
+
+			decodeId, err := ic.Code2Id(code)
+			if err != nil || decodeId != id {
+				t.Fatalf("want %d, got %d %v", id, decodeId, err)
+			}
+		}
+
+		if _, err = ic.Id2Code(ic.MaxId() + 1); err != ErrOutOfRange {
+			t.Fatalf("want ErrOutOfRange, got %v", err)
+		}
+	}
+
+	// 短编码是排列，不会重复
+	ic, _ := NewIdCode(DefaultBase62, defaultSalt, WithCodeLength(2))
+	seen := make(map[string]bool, ic.MaxId())
+	for id := uint64(1); id <= ic.MaxId(); id++ {
+		code, _ := ic.Id2Code(id)
+		if seen[string(code)] {
+			t.Fatalf("duplicate code %s", code)
+		}
+		seen[string(code)] = true
+	}
+}
+
+func TestIdCodeVersion(t *testing.T) {
+	old, _ := NewIdCode(DefaultBase62, 1001, WithCodeVersion(1))
+	current, err := NewIdCode(DefaultBase62, 1002, WithCodeVersion(2), WithHistorySalt(1, 1001))
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	oldCode, _ := old.Id2Code32(888)
+	newCode, _ := current.Id2Code32(888)
+	if len(newCode) != 7 || bytes.Equal(oldCode[1:], newCode[1:]) {
+		t.Fatalf("want different code, got %s %s", oldCode, newCode)
+	}
+
+	for _, code := range [][]byte{oldCode, newCode} {
+		id, err := current.Code2Id(code)
+		if err != nil || id != 888 {
+			t.Fatalf("want 888, got %d %v", id, err)
+		}
+	}
+
+	if _, err = old.Code2Id(newCode); err != ErrCodeVersion {
+		t.Fatalf("want ErrCodeVersion, got %v", err)
+	}
+
+	if _, err = NewIdCode(DefaultBase62, 1, WithCodeVersion(len(DefaultBase62Charset))); err != ErrOutOfRange {
+		t.Fatalf("want ErrOutOfRange, got %v", err)
+	}
+}
+
 // BenchmarkId2Code32-11    	21275890	        56.12 ns/op
 func BenchmarkId2Code32(b *testing.B) {
 	for i := 0; i < b.N; i++ {
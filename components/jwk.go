@@ -0,0 +1,226 @@
+package components
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/grpc-boot/base/v3/utils"
+)
+
+const (
+	JwkTypeRsa = `RSA`
+)
+
+const (
+	JwkUseSig = `sig`
+	JwkUseEnc = `enc`
+)
+
+var (
+	ErrJwkType = errors.New("unsupported jwk key type")
+	ErrJwkData = errors.New("invalid jwk data")
+)
+
+// Jwk RFC 7517 JSON Web Key
+type Jwk struct {
+	Kty string `json:"kty" yaml:"kty"`
+	Kid string `json:"kid,omitempty" yaml:"kid,omitempty"`
+	Use string `json:"use,omitempty" yaml:"use,omitempty"`
+	Alg string `json:"alg,omitempty" yaml:"alg,omitempty"`
+	N   string `json:"n,omitempty" yaml:"n,omitempty"`
+	E   string `json:"e,omitempty" yaml:"e,omitempty"`
+	D   string `json:"d,omitempty" yaml:"d,omitempty"`
+	P   string `json:"p,omitempty" yaml:"p,omitempty"`
+	Q   string `json:"q,omitempty" yaml:"q,omitempty"`
+	Dp  string `json:"dp,omitempty" yaml:"dp,omitempty"`
+	Dq  string `json:"dq,omitempty" yaml:"dq,omitempty"`
+	Qi  string `json:"qi,omitempty" yaml:"qi,omitempty"`
+}
+
+// Jwks RFC 7517 JSON Web Key Set
+type Jwks struct {
+	Keys []Jwk `json:"keys" yaml:"keys"`
+}
+
+// ParseJwks 解析JWKS
+func ParseJwks(data []byte) (*Jwks, error) {
+	var set Jwks
+	if err := utils.JsonUnmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	return &set, nil
+}
+
+// Key 根据kid获取Jwk
+func (s *Jwks) Key(kid string) (jwk Jwk, exists bool) {
+	for _, key := range s.Keys {
+		if key.Kid == kid {
+			return key, true
+		}
+	}
+
+	return
+}
+
+// Add 添加Jwk，已存在的kid会被替换
+func (s *Jwks) Add(jwk Jwk) {
+	for index, key := range s.Keys {
+		if key.Kid == jwk.Kid {
+			s.Keys[index] = jwk
+			return
+		}
+	}
+
+	s.Keys = append(s.Keys, jwk)
+}
+
+// Marshal 序列化为JSON
+func (s *Jwks) Marshal() ([]byte, error) {
+	return utils.JsonMarshal(s)
+}
+
+// NewRsaWithJwk 从Jwk实例化Rsa，含有d时同时导入私钥
+func NewRsaWithJwk(jwk Jwk) (*Rsa, error) {
+	if jwk.D != "" {
+		priKey, err := jwk.RsaPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+
+		return NewRsaWithKey(nil, priKey), nil
+	}
+
+	pubKey, err := jwk.RsaPublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRsaWithKey(pubKey, nil), nil
+}
+
+// RsaPublicKey 获取Rsa公钥
+func (j *Jwk) RsaPublicKey() (*rsa.PublicKey, error) {
+	if j.Kty != JwkTypeRsa {
+		return nil, ErrJwkType
+	}
+
+	n, err := jwkInt(j.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := jwkInt(j.E)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, ErrJwkData
+	}
+
+	return &rsa.PublicKey{
+		N: n,
+		E: int(e.Int64()),
+	}, nil
+}
+
+// RsaPrivateKey 获取Rsa私钥，需要包含d、p、q
+func (j *Jwk) RsaPrivateKey() (*rsa.PrivateKey, error) {
+	pubKey, err := j.RsaPublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		values = []string{j.D, j.P, j.Q}
+		ints   = make([]*big.Int, len(values))
+	)
+
+	for index, value := range values {
+		if ints[index], err = jwkInt(value); err != nil {
+			return nil, err
+		}
+	}
+
+	priKey := &rsa.PrivateKey{
+		PublicKey: *pubKey,
+		D:         ints[0],
+		Primes:    []*big.Int{ints[1], ints[2]},
+	}
+
+	if err = priKey.Validate(); err != nil {
+		return nil, err
+	}
+
+	priKey.Precompute()
+	return priKey, nil
+}
+
+// PublicJwk 导出公钥为Jwk，kid为公钥指纹
+func (r *Rsa) PublicJwk() Jwk {
+	return RsaPublicJwk(r.publicKey)
+}
+
+// PrivateJwk 导出私钥为Jwk
+func (r *Rsa) PrivateJwk() (Jwk, error) {
+	if r.privateKey == nil || len(r.privateKey.Primes) != 2 {
+		return Jwk{}, ErrRsaKey
+	}
+
+	// 不调用Precompute，避免与并发签名同时写共享的私钥
+	var (
+		d    = r.privateKey.D
+		p, q = r.privateKey.Primes[0], r.privateKey.Primes[1]
+		one  = big.NewInt(1)
+	)
+
+	jwk := RsaPublicJwk(&r.privateKey.PublicKey)
+	jwk.D = jwkEncode(d)
+	jwk.P = jwkEncode(p)
+	jwk.Q = jwkEncode(q)
+	jwk.Dp = jwkEncode(new(big.Int).Mod(d, new(big.Int).Sub(p, one)))
+	jwk.Dq = jwkEncode(new(big.Int).Mod(d, new(big.Int).Sub(q, one)))
+	jwk.Qi = jwkEncode(new(big.Int).ModInverse(q, p))
+	return jwk, nil
+}
+
+// RsaPublicJwk 导出Rsa公钥为Jwk
+func RsaPublicJwk(publicKey *rsa.PublicKey) Jwk {
+	return Jwk{
+		Kty: JwkTypeRsa,
+		Kid: RsaThumbprint(publicKey),
+		N:   jwkEncode(publicKey.N),
+		E:   jwkEncode(big.NewInt(int64(publicKey.E))),
+	}
+}
+
+// RsaThumbprint RFC 7638 JWK Thumbprint，SHA-256后base64url编码
+func RsaThumbprint(publicKey *rsa.PublicKey) string {
+	if publicKey == nil {
+		return ""
+	}
+
+	data := `{"e":"` + jwkEncode(big.NewInt(int64(publicKey.E))) + `","kty":"RSA","n":"` + jwkEncode(publicKey.N) + `"}`
+	sum := sha256.Sum256(utils.String2Bytes(data))
+	return utils.Bytes2String(utils.Base64RawUrlEncode(sum[:]))
+}
+
+func jwkEncode(value *big.Int) string {
+	return utils.Bytes2String(utils.Base64RawUrlEncode(value.Bytes()))
+}
+
+func jwkInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, ErrJwkData
+	}
+
+	data, err := utils.Base64RawUrlDecode(utils.String2Bytes(value))
+	if err != nil {
+		return nil, ErrJwkData
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
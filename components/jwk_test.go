@@ -0,0 +1,101 @@
+package components
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"testing"
+)
+
+func TestRsaThumbprint(t *testing.T) {
+	jwk := Jwk{
+		Kty: JwkTypeRsa,
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+	}
+
+	r, err := NewRsaWithJwk(jwk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"
+	if kid := r.Kid(); kid != want {
+		t.Fatalf("want %s, got %s", want, kid)
+	}
+}
+
+func TestParseJwks(t *testing.T) {
+	privateKey, publicKey := CreatePkcs8Keys(2048)
+	r, _ := NewRsa(publicKey, privateKey)
+
+	priJwk, err := r.PrivateJwk()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := &Jwks{}
+	pubJwk := r.PublicJwk()
+	pubJwk.Use = JwkUseSig
+	set.Add(pubJwk)
+
+	data, err := set.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("jwks: %s", data)
+
+	parsed, err := ParseJwks(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwk, exists := parsed.Key(r.Kid())
+	if !exists {
+		t.Fatalf("want true, got %v", exists)
+	}
+
+	verifier, err := NewRsaWithJwk(jwk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := NewRsaWithJwk(priJwk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []byte("jwks payload")
+	sign, err := signer.Sign(msg, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !verifier.Verify(msg, sign, crypto.SHA256) {
+		t.Fatal("want true got false")
+	}
+
+	if _, err = NewRsaWithJwk(Jwk{Kty: "oct"}); err != ErrJwkType {
+		t.Fatalf("want %v, got %v", ErrJwkType, err)
+	}
+}
+
+func TestRsa_PrivateJwk(t *testing.T) {
+	privateKey, publicKey := CreatePkcs8Keys(2048)
+	parsed, _ := NewRsa(publicKey, privateKey)
+
+	// 未预计算的私钥导出时不被修改
+	key := parsed.PrivateKey()
+	raw := &rsa.PrivateKey{PublicKey: key.PublicKey, D: key.D, Primes: key.Primes}
+	jwk, err := NewRsaWithKey(nil, raw).PrivateJwk()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if raw.Precomputed.Dp != nil {
+		t.Fatal("want private key unchanged")
+	}
+
+	if jwk.Dp != jwkEncode(key.Precomputed.Dp) || jwk.Dq != jwkEncode(key.Precomputed.Dq) || jwk.Qi != jwkEncode(key.Precomputed.Qinv) {
+		t.Fatalf("want precomputed values, got %+v", jwk)
+	}
+}
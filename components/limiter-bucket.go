@@ -0,0 +1,130 @@
+package components
+
+import (
+	"math"
+	"time"
+)
+
+// NewTokenBucket 令牌桶，每秒生成rate个令牌，最多累积burst个，rate<=0时不再生成令牌，只能使用初始的burst个
+func NewTokenBucket(rate float64, burst int) Limiter {
+	if !(rate > 0) {
+		rate = 0
+	}
+
+	if burst < 0 {
+		burst = 0
+	}
+
+	return newLimiter(&tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	})
+}
+
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func (tb *tokenBucket) advance(now time.Time) {
+	if tb.last.IsZero() {
+		tb.last = now
+		return
+	}
+
+	if elapsed := now.Sub(tb.last); elapsed > 0 {
+		tb.tokens += elapsed.Seconds() * tb.rate
+		if tb.tokens > tb.burst {
+			tb.tokens = tb.burst
+		}
+		tb.last = now
+	}
+}
+
+func (tb *tokenBucket) reserve(now time.Time, n int, maxWait time.Duration) (time.Time, bool) {
+	if float64(n) > tb.burst || tb.rate <= 0 && float64(n) > tb.tokens {
+		return now, false
+	}
+
+	tb.advance(now)
+
+	var wait time.Duration
+	if remain := tb.tokens - float64(n); remain < 0 {
+		wait = time.Duration(-remain / tb.rate * float64(time.Second))
+	}
+
+	if wait > maxWait {
+		return now, false
+	}
+
+	tb.tokens -= float64(n)
+	return now.Add(wait), true
+}
+
+func (tb *tokenBucket) cancel(now, at time.Time, n int) {
+	tb.advance(now)
+	tb.tokens += float64(n)
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+}
+
+// NewGcra GCRA通用信元速率算法，等价于按速率流出的漏桶，每秒允许rate个事件，最多突发burst个，
+// rate<=0或间隔超出time.Duration范围时拒绝所有事件
+func NewGcra(rate float64, burst int) Limiter {
+	if burst < 0 {
+		burst = 0
+	}
+
+	interval := float64(time.Second) / rate
+	if !(rate > 0) || interval*float64(burst+1) >= math.MaxInt64 {
+		return newLimiter(&gcra{})
+	}
+
+	return newLimiter(&gcra{
+		interval:  time.Duration(interval),
+		tolerance: time.Duration(interval * float64(burst)),
+	})
+}
+
+type gcra struct {
+	// interval 每个事件的发射间隔
+	interval time.Duration
+	// tolerance 允许的突发量对应的时长
+	tolerance time.Duration
+	// tat 理论到达时间
+	tat time.Time
+}
+
+func (g *gcra) reserve(now time.Time, n int, maxWait time.Duration) (time.Time, bool) {
+	if g.interval <= 0 || n > int(g.tolerance/g.interval) {
+		return now, false
+	}
+
+	increment := time.Duration(n) * g.interval
+
+	tat := g.tat
+	if tat.Before(now) {
+		tat = now
+	}
+
+	newTat := tat.Add(increment)
+	at := newTat.Add(-g.tolerance)
+	if at.Before(now) {
+		at = now
+	}
+
+	if at.Sub(now) > maxWait {
+		return now, false
+	}
+
+	g.tat = newTat
+	return at, true
+}
+
+func (g *gcra) cancel(now, at time.Time, n int) {
+	g.tat = g.tat.Add(-time.Duration(n) * g.interval)
+}
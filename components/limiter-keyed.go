@@ -0,0 +1,118 @@
+package components
+
+import (
+	"context"
+	"time"
+
+	"github.com/grpc-boot/base/v3/kind"
+
+	"go.uber.org/atomic"
+)
+
+type keyedItem struct {
+	limiter  Limiter
+	lastUsed atomic.Int64
+}
+
+// KeyedLimiter 按key限流，例如按用户或IP，每个key一个独立的Limiter，空闲超过idleTimeout的key会被清理
+type KeyedLimiter[K kind.Key] struct {
+	items       kind.AtomicShardMap[K]
+	factory     func() Limiter
+	idleTimeout time.Duration
+	done        chan struct{}
+	now         func() time.Time
+}
+
+// NewKeyedLimiter 实例化KeyedLimiter，factory用于为新key创建Limiter，idleTimeout>0时启动后台清理
+func NewKeyedLimiter[K kind.Key](factory func() Limiter, idleTimeout time.Duration) *KeyedLimiter[K] {
+	kl := &KeyedLimiter[K]{
+		items:       kind.NewAtomicShardMap[K](),
+		factory:     factory,
+		idleTimeout: idleTimeout,
+		done:        make(chan struct{}),
+		now:         time.Now,
+	}
+
+	if idleTimeout > 0 {
+		go kl.evictLoop()
+	}
+
+	return kl
+}
+
+// Limiter 获取key对应的Limiter，不存在时创建
+func (kl *KeyedLimiter[K]) Limiter(key K) Limiter {
+	value, exists := kl.items.Get(key)
+	if !exists {
+		value, _ = kl.items.GetOrSet(key, &keyedItem{limiter: kl.factory()})
+	}
+
+	item := value.(*keyedItem)
+	item.lastUsed.Store(kl.now().UnixNano())
+	return item.limiter
+}
+
+// Allow key是否允许一个事件
+func (kl *KeyedLimiter[K]) Allow(key K) bool {
+	return kl.Limiter(key).Allow()
+}
+
+// AllowN key是否允许n个事件
+func (kl *KeyedLimiter[K]) AllowN(key K, n int) bool {
+	return kl.Limiter(key).AllowN(n)
+}
+
+// Wait 等待直到key允许一个事件或ctx结束
+func (kl *KeyedLimiter[K]) Wait(ctx context.Context, key K) error {
+	return kl.Limiter(key).Wait(ctx)
+}
+
+// Reserve 为key预留一个事件
+func (kl *KeyedLimiter[K]) Reserve(key K) *Reservation {
+	return kl.Limiter(key).Reserve()
+}
+
+// Length key数量
+func (kl *KeyedLimiter[K]) Length() int64 {
+	return kl.items.Length()
+}
+
+// Evict 清理空闲超过idleTimeout的key，返回清理数量
+func (kl *KeyedLimiter[K]) Evict() (num int) {
+	deadline := kl.now().Add(-kl.idleTimeout).UnixNano()
+	idle := func(value any) bool {
+		return value.(*keyedItem).lastUsed.Load() < deadline
+	}
+
+	kl.items.Range(func(key K, value any) bool {
+		if idle(value) && kl.items.DeleteIf(key, idle) {
+			num++
+		}
+		return true
+	})
+
+	return num
+}
+
+// Close 停止后台清理
+func (kl *KeyedLimiter[K]) Close() {
+	select {
+	case <-kl.done:
+	default:
+		close(kl.done)
+	}
+}
+
+func (kl *KeyedLimiter[K]) evictLoop() {
+	ticker := time.NewTicker(kl.idleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-kl.done:
+			return
+		case <-ticker.C:
+			kl.Evict()
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package components
+
+import (
+	"sort"
+	"time"
+)
+
+// NewSlidingLog 滑动窗口日志，任意window时长内最多limit个事件，内存占用与limit成正比，
+// limit<0时按0处理，window<=0时拒绝所有事件
+func NewSlidingLog(limit int, window time.Duration) Limiter {
+	if limit < 0 {
+		limit = 0
+	}
+
+	return newLimiter(&slidingLog{
+		limit:  limit,
+		window: window,
+		logs:   make([]time.Time, 0, limit),
+	})
+}
+
+type slidingLog struct {
+	limit  int
+	window time.Duration
+	// logs 按时间升序，预留的事件时间可能晚于当前时间
+	logs []time.Time
+}
+
+func (sl *slidingLog) reserve(now time.Time, n int, maxWait time.Duration) (time.Time, bool) {
+	if n > sl.limit || sl.window <= 0 {
+		return now, false
+	}
+
+	// 清理窗口外的日志
+	expired := sort.Search(len(sl.logs), func(i int) bool {
+		return sl.logs[i].After(now.Add(-sl.window))
+	})
+	sl.logs = append(sl.logs[:0], sl.logs[expired:]...)
+
+	at := now
+	if over := len(sl.logs) + n - sl.limit; over > 0 {
+		// 需要等待最早的over条日志移出窗口
+		if t := sl.logs[over-1].Add(sl.window); t.After(at) {
+			at = t
+		}
+	}
+
+	if at.Sub(now) > maxWait {
+		return now, false
+	}
+
+	index := sort.Search(len(sl.logs), func(i int) bool {
+		return sl.logs[i].After(at)
+	})
+
+	logs := make([]time.Time, 0, len(sl.logs)+n)
+	logs = append(logs, sl.logs[:index]...)
+	for i := 0; i < n; i++ {
+		logs = append(logs, at)
+	}
+	sl.logs = append(logs, sl.logs[index:]...)
+	return at, true
+}
+
+func (sl *slidingLog) cancel(now, at time.Time, n int) {
+	index := sort.Search(len(sl.logs), func(i int) bool {
+		return !sl.logs[i].Before(at)
+	})
+
+	end := index
+	for end < len(sl.logs) && end-index < n && sl.logs[end].Equal(at) {
+		end++
+	}
+
+	sl.logs = append(sl.logs[:index], sl.logs[end:]...)
+}
+
+// NewSlidingCounter 滑动窗口计数，用上一个固定窗口计数按时间加权估算，内存占用固定，
+// limit<0时按0处理，window<=0时拒绝所有事件
+func NewSlidingCounter(limit int, window time.Duration) Limiter {
+	if limit < 0 {
+		limit = 0
+	}
+
+	return newLimiter(&slidingCounter{
+		limit:  float64(limit),
+		window: window,
+	})
+}
+
+type slidingCounter struct {
+	limit  float64
+	window time.Duration
+	start  time.Time
+	prev   float64
+	curr   float64
+	// next 预留到下一个窗口的事件数
+	next float64
+}
+
+func (sc *slidingCounter) advance(now time.Time) {
+	if sc.start.IsZero() {
+		sc.start = now.Truncate(sc.window)
+		return
+	}
+
+	shift := int(now.Sub(sc.start) / sc.window)
+	if shift <= 0 {
+		return
+	}
+
+	for i := 0; i < shift && i < 3; i++ {
+		sc.prev, sc.curr, sc.next = sc.curr, sc.next, 0
+	}
+
+	sc.start = sc.start.Add(time.Duration(shift) * sc.window)
+}
+
+func (sc *slidingCounter) reserve(now time.Time, n int, maxWait time.Duration) (time.Time, bool) {
+	count := float64(n)
+	if count > sc.limit || sc.window <= 0 {
+		return now, false
+	}
+
+	sc.advance(now)
+
+	var (
+		elapsed = now.Sub(sc.start)
+		window  = float64(sc.window)
+		at      = now
+		next    = false
+	)
+
+	switch {
+	case sc.next == 0 && sc.prev*(1-float64(elapsed)/window)+sc.curr+count <= sc.limit:
+	case sc.next == 0 && sc.curr+count <= sc.limit:
+		// 当前窗口内等待上一个窗口的权重下降
+		need := time.Duration(window * (1 - (sc.limit-sc.curr-count)/sc.prev))
+		at = sc.start.Add(need)
+	default:
+		// 下一个窗口内等待当前窗口的权重下降
+		next = true
+		var need time.Duration
+		if sc.curr > 0 {
+			need = time.Duration(window * (1 - (sc.limit-sc.next-count)/sc.curr))
+		}
+
+		if need < 0 {
+			need = 0
+		}
+
+		if sc.next+count > sc.limit {
+			return now, false
+		}
+
+		if need >= sc.window {
+			need = sc.window - 1
+		}
+
+		at = sc.start.Add(sc.window + need)
+	}
+
+	if at.Sub(now) > maxWait {
+		return now, false
+	}
+
+	if next {
+		sc.next += count
+	} else {
+		sc.curr += count
+	}
+
+	return at, true
+}
+
+func (sc *slidingCounter) cancel(now, at time.Time, n int) {
+	sc.advance(now)
+
+	if at.Sub(sc.start) >= sc.window {
+		sc.next -= float64(n)
+		if sc.next < 0 {
+			sc.next = 0
+		}
+		return
+	}
+
+	sc.curr -= float64(n)
+	if sc.curr < 0 {
+		sc.curr = 0
+	}
+}
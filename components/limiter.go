@@ -0,0 +1,170 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	InfDuration = time.Duration(math.MaxInt64)
+)
+
+var (
+	ErrLimitExceeded = errors.New("rate limit exceeded")
+)
+
+// Limiter 限流器，所有方法都是并发安全的
+type Limiter interface {
+	// Allow 是否允许一个事件，不等待
+	Allow() bool
+	// AllowN 是否允许n个事件，不等待
+	AllowN(n int) bool
+	// Wait 等待直到允许一个事件或ctx结束
+	Wait(ctx context.Context) error
+	// WaitN 等待直到允许n个事件或ctx结束，在ctx截止时间前无法满足时立即返回ErrLimitExceeded
+	WaitN(ctx context.Context, n int) error
+	// Reserve 预留一个事件，调用方按Delay等待后执行
+	Reserve() *Reservation
+	// ReserveN 预留n个事件，n超过容量时Reservation.OK()为false
+	ReserveN(n int) *Reservation
+}
+
+// limiterAlgorithm 限流算法，调用方持有锁
+type limiterAlgorithm interface {
+	// reserve 返回n个事件可以执行的时间，超过maxWait时不修改状态并返回false
+	reserve(now time.Time, n int, maxWait time.Duration) (at time.Time, ok bool)
+	// cancel 归还尚未执行的预留
+	cancel(now, at time.Time, n int)
+}
+
+type limiter struct {
+	mutex     sync.Mutex
+	algorithm limiterAlgorithm
+	now       func() time.Time
+}
+
+func newLimiter(algorithm limiterAlgorithm) *limiter {
+	return &limiter{
+		algorithm: algorithm,
+		now:       time.Now,
+	}
+}
+
+func (l *limiter) Allow() bool {
+	return l.AllowN(1)
+}
+
+func (l *limiter) AllowN(n int) bool {
+	return l.reserveN(n, 0).ok
+}
+
+func (l *limiter) Wait(ctx context.Context) error {
+	return l.WaitN(ctx, 1)
+}
+
+func (l *limiter) WaitN(ctx context.Context, n int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	maxWait := InfDuration
+	if deadline, ok := ctx.Deadline(); ok {
+		maxWait = deadline.Sub(l.now())
+	}
+
+	r := l.reserveN(n, maxWait)
+	if !r.ok {
+		return ErrLimitExceeded
+	}
+
+	delay := r.Delay()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
+
+func (l *limiter) Reserve() *Reservation {
+	return l.ReserveN(1)
+}
+
+func (l *limiter) ReserveN(n int) *Reservation {
+	return l.reserveN(n, InfDuration)
+}
+
+func (l *limiter) reserveN(n int, maxWait time.Duration) *Reservation {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if n < 1 {
+		return &Reservation{ok: true, at: l.now(), limiter: l}
+	}
+
+	at, ok := l.algorithm.reserve(l.now(), n, maxWait)
+	return &Reservation{
+		ok:      ok,
+		at:      at,
+		n:       n,
+		limiter: l,
+	}
+}
+
+// Reservation 预留结果
+type Reservation struct {
+	ok      bool
+	at      time.Time
+	n       int
+	limiter *limiter
+	once    sync.Once
+}
+
+// OK 是否预留成功
+func (r *Reservation) OK() bool {
+	return r.ok
+}
+
+// Delay 需要等待的时间，预留失败时返回InfDuration
+func (r *Reservation) Delay() time.Duration {
+	if !r.ok {
+		return InfDuration
+	}
+
+	delay := r.at.Sub(r.limiter.now())
+	if delay < 0 {
+		return 0
+	}
+
+	return delay
+}
+
+// Cancel 取消尚未到期的预留，归还配额
+func (r *Reservation) Cancel() {
+	if !r.ok || r.n < 1 {
+		return
+	}
+
+	r.once.Do(func() {
+		r.limiter.mutex.Lock()
+		defer r.limiter.mutex.Unlock()
+
+		now := r.limiter.now()
+		if r.at.After(now) {
+			r.limiter.algorithm.cancel(now, r.at, r.n)
+		}
+	})
+}
@@ -0,0 +1,218 @@
+package components
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (fc *fakeClock) Now() time.Time {
+	return fc.now
+}
+
+func (fc *fakeClock) Add(d time.Duration) {
+	fc.now = fc.now.Add(d)
+}
+
+func withClock(l Limiter) (*limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	lim := l.(*limiter)
+	lim.now = clock.Now
+	return lim, clock
+}
+
+func TestLimiterBurst(t *testing.T) {
+	cases := map[string]Limiter{
+		"tokenBucket":    NewTokenBucket(10, 5),
+		"gcra":           NewGcra(10, 5),
+		"slidingLog":     NewSlidingLog(5, time.Millisecond*500),
+		"slidingCounter": NewSlidingCounter(5, time.Millisecond*500),
+	}
+
+	for name, l := range cases {
+		lim, clock := withClock(l)
+
+		for i := 0; i < 5; i++ {
+			if !lim.Allow() {
+				t.Fatalf("%s: want true at %d, got false", name, i)
+			}
+		}
+
+		if lim.Allow() {
+			t.Fatalf("%s: want false, got true", name)
+		}
+
+		if lim.AllowN(6) {
+			t.Fatalf("%s: want false for n > burst, got true", name)
+		}
+
+		if r := lim.ReserveN(6); r.OK() || r.Delay() != InfDuration {
+			t.Fatalf("%s: want failed reservation, got %v", name, r.Delay())
+		}
+
+		clock.Add(time.Second * 2)
+		if !lim.AllowN(5) {
+			t.Fatalf("%s: want true after refill, got false", name)
+		}
+	}
+}
+
+func TestLimiterReserve(t *testing.T) {
+	cases := map[string]struct {
+		limiter Limiter
+		delay   time.Duration
+	}{
+		"tokenBucket": {NewTokenBucket(10, 1), time.Millisecond * 100},
+		"gcra":        {NewGcra(10, 1), time.Millisecond * 100},
+		"slidingLog":  {NewSlidingLog(1, time.Millisecond*100), time.Millisecond * 100},
+	}
+
+	for name, c := range cases {
+		lim, clock := withClock(c.limiter)
+
+		if r := lim.Reserve(); !r.OK() || r.Delay() != 0 {
+			t.Fatalf("%s: want 0, got %v", name, r.Delay())
+		}
+
+		r := lim.Reserve()
+		if !r.OK() || r.Delay() != c.delay {
+			t.Fatalf("%s: want %v, got %v", name, c.delay, r.Delay())
+		}
+
+		// 取消后配额归还
+		r.Cancel()
+		if r = lim.Reserve(); r.Delay() != c.delay {
+			t.Fatalf("%s: want %v, got %v", name, c.delay, r.Delay())
+		}
+
+		clock.Add(c.delay)
+		if r.Delay() != 0 {
+			t.Fatalf("%s: want 0, got %v", name, r.Delay())
+		}
+	}
+}
+
+func TestSlidingCounter(t *testing.T) {
+	lim, clock := withClock(NewSlidingCounter(10, time.Second))
+	clock.now = clock.now.Truncate(time.Second)
+
+	if !lim.AllowN(10) {
+		t.Fatalf("want true, got false")
+	}
+
+	// 下一个窗口的前半段，上一个窗口权重为0.5，估算值为5
+	clock.Add(time.Millisecond * 1500)
+	if !lim.AllowN(5) {
+		t.Fatalf("want true, got false")
+	}
+
+	if lim.Allow() {
+		t.Fatalf("want false, got true")
+	}
+
+	r := lim.Reserve()
+	if !r.OK() || r.Delay() != time.Millisecond*100 {
+		t.Fatalf("want 100ms, got %v", r.Delay())
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	l := NewTokenBucket(100, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Millisecond*5 {
+		t.Fatalf("want wait about 10ms, got %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	if err := NewTokenBucket(1, 1).WaitN(ctx, 2); err != ErrLimitExceeded {
+		t.Fatalf("want ErrLimitExceeded, got %v", err)
+	}
+
+	l = NewTokenBucket(1, 1)
+	l.Allow()
+	if err := l.Wait(ctx); err != ErrLimitExceeded {
+		t.Fatalf("want ErrLimitExceeded, got %v", err)
+	}
+}
+
+func TestLimiterInvalidRate(t *testing.T) {
+	// 速率不合法时不会除零，GCRA拒绝所有事件，令牌桶只能使用初始令牌
+	for _, rate := range []float64{0, -1, math.NaN(), 1e-12} {
+		if NewGcra(rate, 5).Allow() {
+			t.Fatalf("gcra %v want false, got true", rate)
+		}
+	}
+
+	l := NewTokenBucket(-1, 2)
+	if !l.AllowN(2) || l.Allow() {
+		t.Fatal("want only burst tokens")
+	}
+
+	if NewTokenBucket(10, -1).Allow() {
+		t.Fatal("want false, got true")
+	}
+}
+
+func TestLimiterInvalidWindow(t *testing.T) {
+	// limit或window不合法时不会panic或除零
+	for _, newLimiter := range []func(int, time.Duration) Limiter{NewSlidingLog, NewSlidingCounter} {
+		for _, window := range []time.Duration{0, -time.Second} {
+			l := newLimiter(5, window)
+			if l.Allow() {
+				t.Fatalf("window %v want false, got true", window)
+			}
+		}
+
+		if newLimiter(-1, time.Second).Allow() {
+			t.Fatal("want false, got true")
+		}
+	}
+}
+
+func TestKeyedLimiter(t *testing.T) {
+	kl := NewKeyedLimiter[string](func() Limiter {
+		return NewTokenBucket(1, 2)
+	}, 0)
+	defer kl.Close()
+
+	clock := &fakeClock{now: time.Now()}
+	kl.now = clock.Now
+	kl.idleTimeout = time.Minute
+
+	for i := 0; i < 2; i++ {
+		if !kl.Allow("1.1.1.1") {
+			t.Fatalf("want true, got false")
+		}
+	}
+
+	if kl.Allow("1.1.1.1") {
+		t.Fatalf("want false, got true")
+	}
+
+	if !kl.Allow("2.2.2.2") || kl.Length() != 2 {
+		t.Fatalf("want 2 keys, got %d", kl.Length())
+	}
+
+	clock.Add(time.Second * 30)
+	kl.Allow("2.2.2.2")
+
+	clock.Add(time.Second * 40)
+	if num := kl.Evict(); num != 1 || kl.Length() != 1 {
+		t.Fatalf("want 1 evicted, got %d %d", num, kl.Length())
+	}
+}
@@ -0,0 +1,319 @@
+package components
+
+import (
+	"crypto"
+	"crypto/rand"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grpc-boot/base/v3/utils"
+)
+
+var (
+	ErrOtpSecret  = errors.New("invalid otp secret")
+	ErrOtpInvalid = errors.New("invalid otp code")
+	ErrOtpReplay  = errors.New("otp code already used")
+	ErrOtpHash    = errors.New("otp hash must be SHA1, SHA256 or SHA512")
+)
+
+const (
+	otpStoreSweep = 1024
+)
+
+var (
+	otpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+	otpPowers   = [...]uint32{1, 10, 100, 1000, 10000, 100000, 1000000, 10000000, 100000000}
+)
+
+// OtpStore 记录每个账号最后接受的TOTP计数器，防止code在有效期内被重放(RFC 6238 5.2)
+type OtpStore interface {
+	// Accept counter大于account上次接受的计数器时记录并保留ttl时间，否则返回false
+	Accept(account string, counter uint64, ttl time.Duration) (ok bool)
+}
+
+// OtpOption 一次性密码选项
+type OtpOption func(o *Otp)
+
+// WithOtpDigits 位数[6, 8]，默认6
+func WithOtpDigits(digits int) OtpOption {
+	return func(o *Otp) {
+		o.digits = digits
+	}
+}
+
+// WithOtpPeriod TOTP时间步长，默认30秒
+func WithOtpPeriod(period time.Duration) OtpOption {
+	return func(o *Otp) {
+		o.period = period
+	}
+}
+
+// WithOtpHash 哈希算法，支持crypto.SHA1、crypto.SHA256、crypto.SHA512，默认SHA1
+func WithOtpHash(hash crypto.Hash) OtpOption {
+	return func(o *Otp) {
+		o.hash = hash
+	}
+}
+
+// WithOtpSkew 校验时允许前后偏差的步数，TOTP默认1，HOTP为向后查找的计数器数量
+func WithOtpSkew(skew int) OtpOption {
+	return func(o *Otp) {
+		o.skew = skew
+	}
+}
+
+// WithOtpStore 防重放存储，默认不检查重放
+func WithOtpStore(store OtpStore) OtpOption {
+	return func(o *Otp) {
+		o.store = store
+	}
+}
+
+// WithOtpIssuer otpauth URI中的发行方
+func WithOtpIssuer(issuer string) OtpOption {
+	return func(o *Otp) {
+		o.issuer = issuer
+	}
+}
+
+// Otp RFC 4226 HOTP和RFC 6238 TOTP，secret为无填充的base32编码
+type Otp struct {
+	digits int
+	period time.Duration
+	hash   crypto.Hash
+	skew   int
+	store  OtpStore
+	issuer string
+	now    func() time.Time
+}
+
+// NewOtp 实例化一次性密码
+func NewOtp(opts ...OtpOption) (*Otp, error) {
+	o := &Otp{
+		digits: 6,
+		period: time.Second * 30,
+		hash:   crypto.SHA1,
+		skew:   1,
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.hash != crypto.SHA1 && o.hash != crypto.SHA256 && o.hash != crypto.SHA512 {
+		return nil, ErrOtpHash
+	}
+
+	if o.digits < 6 || o.digits > 8 || o.period < time.Second || o.skew < 0 {
+		return nil, ErrOutOfRange
+	}
+
+	return o, nil
+}
+
+// GenerateOtpSecret 生成size字节的随机secret，size<16时使用20
+func GenerateOtpSecret(size int) (string, error) {
+	if size < 16 {
+		size = 20
+	}
+
+	key := make([]byte, size)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return otpEncoding.EncodeToString(key), nil
+}
+
+// Hotp 计数器为counter的code
+func (o *Otp) Hotp(secret string, counter uint64) (string, error) {
+	key, err := decodeOtpSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return o.code(key, counter)
+}
+
+// VerifyHotp 在[counter, counter+skew]中查找匹配的code，成功时返回下一次应使用的计数器
+func (o *Otp) VerifyHotp(secret, code string, counter uint64) (next uint64, err error) {
+	key, err := decodeOtpSecret(secret)
+	if err != nil {
+		return counter, err
+	}
+
+	for offset := 0; offset <= o.skew; offset++ {
+		if o.equal(key, counter+uint64(offset), code) {
+			return counter + uint64(offset) + 1, nil
+		}
+	}
+
+	return counter, ErrOtpInvalid
+}
+
+// Totp 当前时间的code
+func (o *Otp) Totp(secret string) (string, error) {
+	return o.TotpAt(secret, o.now())
+}
+
+// TotpAt 指定时间的code
+func (o *Otp) TotpAt(secret string, t time.Time) (string, error) {
+	return o.Hotp(secret, o.counter(t))
+}
+
+// VerifyTotp 校验当前时间前后skew个步长内的code，设置了OtpStore时同一account不能再使用不晚于上次接受的code
+func (o *Otp) VerifyTotp(account, secret, code string) error {
+	key, err := decodeOtpSecret(secret)
+	if err != nil {
+		return err
+	}
+
+	current := o.counter(o.now())
+	for offset := -o.skew; offset <= o.skew; offset++ {
+		counter := current + uint64(offset)
+		if offset < 0 && current < uint64(-offset) {
+			continue
+		}
+
+		if !o.equal(key, counter, code) {
+			continue
+		}
+
+		// 保留到不晚于该计数器的code全部失效
+		if o.store != nil && !o.store.Accept(account, counter, o.period*time.Duration(2*o.skew+1)) {
+			return ErrOtpReplay
+		}
+
+		return nil
+	}
+
+	return ErrOtpInvalid
+}
+
+// TotpUri otpauth://totp 配置URI，用于生成二维码
+func (o *Otp) TotpUri(account, secret string) string {
+	return o.uri("totp", account, secret, url.Values{
+		"period": {strconv.Itoa(int(o.period / time.Second))},
+	})
+}
+
+// HotpUri otpauth://hotp 配置URI，用于生成二维码
+func (o *Otp) HotpUri(account, secret string, counter uint64) string {
+	return o.uri("hotp", account, secret, url.Values{
+		"counter": {strconv.FormatUint(counter, 10)},
+	})
+}
+
+func (o *Otp) uri(otpType, account, secret string, query url.Values) string {
+	label := account
+	if o.issuer != "" {
+		label = o.issuer + ":" + account
+		query.Set("issuer", o.issuer)
+	}
+
+	query.Set("secret", secret)
+	query.Set("algorithm", strings.ReplaceAll(o.hash.String(), "-", ""))
+	query.Set("digits", strconv.Itoa(o.digits))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     otpType,
+		Path:     "/" + label,
+		RawQuery: query.Encode(),
+	}
+
+	return u.String()
+}
+
+func (o *Otp) counter(t time.Time) uint64 {
+	return uint64(t.Unix() / int64(o.period/time.Second))
+}
+
+func (o *Otp) code(key []byte, counter uint64) (string, error) {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	sum, err := utils.HexDecode(utils.HMacBytes(key, msg[:], o.hash))
+	if err != nil {
+		return "", err
+	}
+
+	// RFC 4226 动态截断
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	code := strconv.FormatUint(uint64(value%otpPowers[o.digits]), 10)
+	if len(code) < o.digits {
+		code = strings.Repeat("0", o.digits-len(code)) + code
+	}
+
+	return code, nil
+}
+
+func (o *Otp) equal(key []byte, counter uint64, code string) bool {
+	expected, err := o.code(key, counter)
+	return err == nil && subtle.ConstantTimeCompare(utils.String2Bytes(expected), utils.String2Bytes(code)) == 1
+}
+
+func decodeOtpSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.TrimRight(strings.ReplaceAll(secret, " ", ""), "="))
+	key, err := otpEncoding.DecodeString(secret)
+	if err != nil || len(key) == 0 {
+		return nil, ErrOtpSecret
+	}
+
+	return key, nil
+}
+
+// MemoryOtpStore 内存防重放存储，多实例部署时应使用共享存储
+type MemoryOtpStore struct {
+	mutex    sync.Mutex
+	accepted map[string]otpAccepted
+	now      func() time.Time
+}
+
+type otpAccepted struct {
+	counter  uint64
+	expireAt time.Time
+}
+
+// NewMemoryOtpStore 实例化内存防重放存储
+func NewMemoryOtpStore() *MemoryOtpStore {
+	return &MemoryOtpStore{
+		accepted: make(map[string]otpAccepted),
+		now:      time.Now,
+	}
+}
+
+func (ms *MemoryOtpStore) Accept(account string, counter uint64, ttl time.Duration) bool {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	now := ms.now()
+	if last, exists := ms.accepted[account]; exists && last.expireAt.After(now) && counter <= last.counter {
+		return false
+	}
+
+	// 记录较多时顺带清理过期记录
+	if len(ms.accepted) >= otpStoreSweep {
+		for key, last := range ms.accepted {
+			if !last.expireAt.After(now) {
+				delete(ms.accepted, key)
+			}
+		}
+	}
+
+	ms.accepted[account] = otpAccepted{counter: counter, expireAt: now.Add(ttl)}
+	return true
+}
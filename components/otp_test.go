@@ -0,0 +1,147 @@
+package components
+
+import (
+	"crypto"
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func otpSecret(seed string) string {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte(seed))
+}
+
+func TestOtp_Hotp(t *testing.T) {
+	// RFC 4226 附录D
+	var (
+		secret = otpSecret("12345678901234567890")
+		want   = []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	)
+
+	o, err := NewOtp(WithOtpSkew(2))
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	for counter, code := range want {
+		got, err := o.Hotp(secret, uint64(counter))
+		if err != nil || got != code {
+			t.Fatalf("counter %d: want %s, got %s %v", counter, code, got, err)
+		}
+	}
+
+	if next, err := o.VerifyHotp(secret, "969429", 1); err != nil || next != 4 {
+		t.Fatalf("want 4, got %d %v", next, err)
+	}
+
+	if _, err = o.VerifyHotp(secret, "338314", 1); err != ErrOtpInvalid {
+		t.Fatalf("want ErrOtpInvalid, got %v", err)
+	}
+
+	if _, err = o.Hotp("not base32!", 0); err != ErrOtpSecret {
+		t.Fatalf("want ErrOtpSecret, got %v", err)
+	}
+}
+
+func TestOtp_Totp(t *testing.T) {
+	// RFC 6238 附录B
+	cases := []struct {
+		hash crypto.Hash
+		seed string
+		unix int64
+		want string
+	}{
+		{crypto.SHA1, "12345678901234567890", 59, "94287082"},
+		{crypto.SHA256, "12345678901234567890123456789012", 59, "46119246"},
+		{crypto.SHA512, "1234567890123456789012345678901234567890123456789012345678901234", 59, "90693936"},
+		{crypto.SHA1, "12345678901234567890", 1111111109, "07081804"},
+		{crypto.SHA256, "12345678901234567890123456789012", 1111111109, "68084774"},
+		{crypto.SHA512, "1234567890123456789012345678901234567890123456789012345678901234", 1111111109, "25091201"},
+	}
+
+	for _, c := range cases {
+		o, _ := NewOtp(WithOtpDigits(8), WithOtpHash(c.hash))
+		got, err := o.TotpAt(otpSecret(c.seed), time.Unix(c.unix, 0))
+		if err != nil || got != c.want {
+			t.Fatalf("%v %d: want %s, got %s %v", c.hash, c.unix, c.want, got, err)
+		}
+	}
+
+	if _, err := NewOtp(WithOtpHash(crypto.MD5)); err != ErrOtpHash {
+		t.Fatalf("want ErrOtpHash, got %v", err)
+	}
+
+	if _, err := NewOtp(WithOtpDigits(4)); err != ErrOutOfRange {
+		t.Fatalf("want ErrOutOfRange, got %v", err)
+	}
+}
+
+func TestOtp_VerifyTotp(t *testing.T) {
+	var (
+		clock  = &fakeClock{now: time.Unix(1700000000, 0)}
+		store  = NewMemoryOtpStore()
+		o, _   = NewOtp(WithOtpStore(store))
+		secret string
+		err    error
+	)
+	o.now, store.now = clock.Now, clock.Now
+
+	if secret, err = GenerateOtpSecret(0); err != nil || len(secret) != 32 {
+		t.Fatalf("want 32 chars secret, got %s %v", secret, err)
+	}
+
+	// 上一个步长的code在偏差范围内
+	previous, _ := o.TotpAt(secret, clock.Now().Add(-time.Second*30))
+	if err = o.VerifyTotp("admin", secret, previous); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if err = o.VerifyTotp("admin", secret, previous); err != ErrOtpReplay {
+		t.Fatalf("want ErrOtpReplay, got %v", err)
+	}
+
+	// 使用当前code后，更早的code即使仍在偏差范围内也不能使用
+	current, _ := o.Totp(secret)
+	if err = o.VerifyTotp("admin", secret, current); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if err = o.VerifyTotp("other", secret, current); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if err = o.VerifyTotp("other", secret, previous); err != ErrOtpReplay {
+		t.Fatalf("want ErrOtpReplay, got %v", err)
+	}
+
+	stale, _ := o.TotpAt(secret, clock.Now().Add(-time.Second*90))
+	if err = o.VerifyTotp("admin", secret, stale); err != ErrOtpInvalid {
+		t.Fatalf("want ErrOtpInvalid, got %v", err)
+	}
+}
+
+func TestOtp_Uri(t *testing.T) {
+	o, _ := NewOtp(WithOtpIssuer("Grpc Boot"), WithOtpHash(crypto.SHA256))
+	uri := o.TotpUri("admin@example.com", "JBSWY3DPEHPK3PXP")
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	query := u.Query()
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Grpc Boot:admin@example.com" {
+		t.Fatalf("want otpauth://totp/label, got %s", uri)
+	}
+
+	if query.Get("secret") != "JBSWY3DPEHPK3PXP" || query.Get("issuer") != "Grpc Boot" || query.Get("algorithm") != "SHA256" ||
+		query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Fatalf("want full query, got %s", uri)
+	}
+
+	hotp := o.HotpUri("admin", "JBSWY3DPEHPK3PXP", 7)
+	if u, err = url.Parse(hotp); err != nil || u.Host != "hotp" || u.Query().Get("counter") != "7" {
+		t.Fatalf("want counter 7, got %s", hotp)
+	}
+}
@@ -0,0 +1,378 @@
+package components
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+
+	"github.com/grpc-boot/base/v3/kind"
+)
+
+/**
+ * MessagePack格式的Param编码，支持nil、bool、整数、浮点数、string、[]byte、切片和string为键的map
+ * 为了与JSON解码结果一致，数字统一解码为float64，map解码为map[string]any，切片解码为[]any
+ */
+
+var (
+	ErrPayloadType   = errors.New("unsupported payload value type")
+	ErrPayloadFormat = errors.New("invalid binary payload")
+)
+
+const (
+	maxPayloadDepth = 64
+)
+
+type binaryPayload struct{}
+
+func (binaryPayload) Type() uint8 {
+	return PayloadBinary
+}
+
+func (binaryPayload) Marshal(param kind.JsonParam) ([]byte, error) {
+	if param == nil {
+		return nil, nil
+	}
+
+	return appendMsgpack(nil, reflect.ValueOf(map[string]any(param)), 0)
+}
+
+func (binaryPayload) Unmarshal(data []byte) (kind.JsonParam, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	d := &msgpackDecoder{data: data}
+	value, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.offset != len(data) {
+		return nil, ErrPayloadFormat
+	}
+
+	param, ok := value.(map[string]any)
+	if !ok {
+		return nil, ErrPayloadFormat
+	}
+
+	return param, nil
+}
+
+func appendMsgpack(dst []byte, v reflect.Value, depth int) ([]byte, error) {
+	if depth > maxPayloadDepth {
+		return nil, ErrPayloadType
+	}
+
+	if !v.IsValid() {
+		return append(dst, 0xc0), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return append(dst, 0xc0), nil
+		}
+		return appendMsgpack(dst, v.Elem(), depth)
+	case reflect.Bool:
+		if v.Bool() {
+			return append(dst, 0xc3), nil
+		}
+		return append(dst, 0xc2), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendMsgpackInt(dst, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendMsgpackUint(dst, v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		dst = append(dst, 0xcb)
+		return binary.BigEndian.AppendUint64(dst, math.Float64bits(v.Float())), nil
+	case reflect.String:
+		return appendMsgpackString(dst, v.String()), nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return appendMsgpackBytes(dst, v.Bytes()), nil
+		}
+
+		dst = appendMsgpackHeader(dst, v.Len(), 0x90, 0xdc)
+		var err error
+		for i := 0; i < v.Len(); i++ {
+			if dst, err = appendMsgpack(dst, v.Index(i), depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, ErrPayloadType
+		}
+
+		dst = appendMsgpackHeader(dst, v.Len(), 0x80, 0xde)
+		var err error
+		iter := v.MapRange()
+		for iter.Next() {
+			dst = appendMsgpackString(dst, iter.Key().String())
+			if dst, err = appendMsgpack(dst, iter.Value(), depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	}
+
+	return nil, ErrPayloadType
+}
+
+func appendMsgpackInt(dst []byte, value int64) []byte {
+	switch {
+	case value >= 0:
+		return appendMsgpackUint(dst, uint64(value))
+	case value >= -32:
+		return append(dst, byte(value))
+	case value >= math.MinInt8:
+		return append(dst, 0xd0, byte(value))
+	case value >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xd1), uint16(value))
+	case value >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xd2), uint32(value))
+	}
+
+	return binary.BigEndian.AppendUint64(append(dst, 0xd3), uint64(value))
+}
+
+func appendMsgpackUint(dst []byte, value uint64) []byte {
+	switch {
+	case value <= 0x7f:
+		return append(dst, byte(value))
+	case value <= math.MaxUint8:
+		return append(dst, 0xcc, byte(value))
+	case value <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xcd), uint16(value))
+	case value <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xce), uint32(value))
+	}
+
+	return binary.BigEndian.AppendUint64(append(dst, 0xcf), value)
+}
+
+func appendMsgpackString(dst []byte, value string) []byte {
+	switch length := len(value); {
+	case length < 32:
+		dst = append(dst, 0xa0|byte(length))
+	case length <= math.MaxUint8:
+		dst = append(dst, 0xd9, byte(length))
+	case length <= math.MaxUint16:
+		dst = binary.BigEndian.AppendUint16(append(dst, 0xda), uint16(length))
+	default:
+		dst = binary.BigEndian.AppendUint32(append(dst, 0xdb), uint32(length))
+	}
+
+	return append(dst, value...)
+}
+
+func appendMsgpackBytes(dst []byte, value []byte) []byte {
+	switch length := len(value); {
+	case length <= math.MaxUint8:
+		dst = append(dst, 0xc4, byte(length))
+	case length <= math.MaxUint16:
+		dst = binary.BigEndian.AppendUint16(append(dst, 0xc5), uint16(length))
+	default:
+		dst = binary.BigEndian.AppendUint32(append(dst, 0xc6), uint32(length))
+	}
+
+	return append(dst, value...)
+}
+
+// appendMsgpackHeader 数组和map的头部，fix为fixarray/fixmap前缀，code16为16位长度的类型码，32位类型码为code16+1
+func appendMsgpackHeader(dst []byte, length int, fix, code16 byte) []byte {
+	switch {
+	case length < 16:
+		return append(dst, fix|byte(length))
+	case length <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, code16), uint16(length))
+	}
+
+	return binary.BigEndian.AppendUint32(append(dst, code16+1), uint32(length))
+}
+
+type msgpackDecoder struct {
+	data   []byte
+	offset int
+}
+
+func (d *msgpackDecoder) next(size int) ([]byte, error) {
+	if size < 0 || len(d.data)-d.offset < size {
+		return nil, ErrPayloadFormat
+	}
+
+	data := d.data[d.offset : d.offset+size]
+	d.offset += size
+	return data, nil
+}
+
+func (d *msgpackDecoder) length(size int) (int, error) {
+	data, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+
+	switch size {
+	case 1:
+		return int(data[0]), nil
+	case 2:
+		return int(binary.BigEndian.Uint16(data)), nil
+	}
+
+	return int(binary.BigEndian.Uint32(data)), nil
+}
+
+func (d *msgpackDecoder) decode(depth int) (any, error) {
+	if depth > maxPayloadDepth {
+		return nil, ErrPayloadFormat
+	}
+
+	head, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+
+	code := head[0]
+	switch {
+	case code <= 0x7f:
+		return float64(code), nil
+	case code >= 0xe0:
+		return float64(int8(code)), nil
+	case code&0xf0 == 0x80:
+		return d.decodeMap(int(code&0x0f), depth)
+	case code&0xf0 == 0x90:
+		return d.decodeArray(int(code&0x0f), depth)
+	case code&0xe0 == 0xa0:
+		return d.decodeString(int(code & 0x1f))
+	}
+
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		length, err := d.length(1 << (code - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := d.next(length)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, data...), nil
+	case 0xca:
+		data, err := d.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+	case 0xcb:
+		data, err := d.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		data, err := d.next(1 << (code - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+
+		var value uint64
+		for _, b := range data {
+			value = value<<8 | uint64(b)
+		}
+		return float64(value), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		data, err := d.next(1 << (code - 0xd0))
+		if err != nil {
+			return nil, err
+		}
+
+		// 符号扩展
+		value := int64(int8(data[0]))
+		for _, b := range data[1:] {
+			value = value<<8 | int64(b)
+		}
+		return float64(value), nil
+	case 0xd9, 0xda, 0xdb:
+		length, err := d.length(1 << (code - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(length)
+	case 0xdc, 0xdd:
+		length, err := d.length(2 << (code - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(length, depth)
+	case 0xde, 0xdf:
+		length, err := d.length(2 << (code - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(length, depth)
+	}
+
+	return nil, ErrPayloadFormat
+}
+
+func (d *msgpackDecoder) decodeString(length int) (any, error) {
+	data, err := d.next(length)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+func (d *msgpackDecoder) decodeArray(length int, depth int) (any, error) {
+	// 每个元素至少1字节，防止恶意长度导致大量分配
+	if length > len(d.data)-d.offset {
+		return nil, ErrPayloadFormat
+	}
+
+	list := make([]any, length)
+	for i := range list {
+		value, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = value
+	}
+
+	return list, nil
+}
+
+func (d *msgpackDecoder) decodeMap(length int, depth int) (any, error) {
+	if length*2 > len(d.data)-d.offset {
+		return nil, ErrPayloadFormat
+	}
+
+	m := make(map[string]any, length)
+	for i := 0; i < length; i++ {
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := key.(string)
+		if !ok {
+			return nil, ErrPayloadFormat
+		}
+
+		if m[name], err = d.decode(depth + 1); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,333 @@
+package components
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/grpc-boot/base/v3/kind"
+	"github.com/grpc-boot/base/v3/utils"
+)
+
+/**
+ * 帧格式（大端）：
+ * 头部：magic(2) | version(1) | payloadType(1) | length(4)
+ * 帧体：id(2) | nameLen(1) | name | payload，length为帧体长度
+ */
+
+const (
+	PackageMagic    uint16 = 0x4742
+	PackageVersion1 uint8  = 1
+)
+
+const (
+	PackageHeaderSize   = 8
+	DefaultMaxFrameSize = 4 << 20
+)
+
+const (
+	PayloadJson   uint8 = 1
+	PayloadBinary uint8 = 2
+)
+
+var (
+	ErrPackageMagic    = errors.New("invalid package magic")
+	ErrPackageVersion  = errors.New("unsupported package version")
+	ErrPackageTooLarge = errors.New("package frame too large")
+	ErrPackageName     = errors.New("package name length must be [0, 255]")
+	ErrPayloadCodec    = errors.New("unknown payload codec")
+)
+
+var (
+	payloadCodecs = map[uint8]PayloadCodec{
+		PayloadJson:   jsonPayload{},
+		PayloadBinary: binaryPayload{},
+	}
+	payloadMutex sync.RWMutex
+)
+
+var (
+	DefaultPackageCodec = NewPackageCodec()
+)
+
+// PayloadCodec Package.Param编解码
+type PayloadCodec interface {
+	Type() uint8
+	Marshal(param kind.JsonParam) ([]byte, error)
+	Unmarshal(data []byte) (kind.JsonParam, error)
+}
+
+// RegisterPayloadCodec 注册Param编解码，已存在的类型会被替换
+func RegisterPayloadCodec(codec PayloadCodec) {
+	payloadMutex.Lock()
+	defer payloadMutex.Unlock()
+
+	payloadCodecs[codec.Type()] = codec
+}
+
+func loadPayloadCodec(payloadType uint8) (PayloadCodec, error) {
+	payloadMutex.RLock()
+	defer payloadMutex.RUnlock()
+
+	codec, exists := payloadCodecs[payloadType]
+	if !exists {
+		return nil, ErrPayloadCodec
+	}
+
+	return codec, nil
+}
+
+type jsonPayload struct{}
+
+func (jsonPayload) Type() uint8 {
+	return PayloadJson
+}
+
+func (jsonPayload) Marshal(param kind.JsonParam) ([]byte, error) {
+	if param == nil {
+		return nil, nil
+	}
+
+	return utils.JsonMarshal(param)
+}
+
+func (jsonPayload) Unmarshal(data []byte) (kind.JsonParam, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var param kind.JsonParam
+	if err := utils.JsonUnmarshal(data, &param); err != nil {
+		return nil, err
+	}
+
+	return param, nil
+}
+
+// PackageCodecOption PackageCodec选项
+type PackageCodecOption func(pc *PackageCodec)
+
+// WithPayloadType 编码使用的Param编解码类型，默认PayloadJson
+func WithPayloadType(payloadType uint8) PackageCodecOption {
+	return func(pc *PackageCodec) {
+		pc.payloadType = payloadType
+	}
+}
+
+// WithMaxFrameSize 帧体最大长度，默认DefaultMaxFrameSize
+func WithMaxFrameSize(size int) PackageCodecOption {
+	return func(pc *PackageCodec) {
+		pc.maxFrameSize = size
+	}
+}
+
+// PackageCodec Package帧编解码，解码时根据帧头部的payloadType选择Param编解码
+type PackageCodec struct {
+	payloadType  uint8
+	maxFrameSize int
+}
+
+// NewPackageCodec 实例化PackageCodec
+func NewPackageCodec(opts ...PackageCodecOption) *PackageCodec {
+	pc := &PackageCodec{
+		payloadType:  PayloadJson,
+		maxFrameSize: DefaultMaxFrameSize,
+	}
+
+	for _, opt := range opts {
+		opt(pc)
+	}
+
+	return pc
+}
+
+// MaxFrameSize 帧体最大长度
+func (pc *PackageCodec) MaxFrameSize() int {
+	return pc.maxFrameSize
+}
+
+// Encode 编码为完整的帧
+func (pc *PackageCodec) Encode(p *Package) ([]byte, error) {
+	return pc.AppendEncode(nil, p)
+}
+
+// AppendEncode 编码帧并追加到dst
+func (pc *PackageCodec) AppendEncode(dst []byte, p *Package) ([]byte, error) {
+	if len(p.Name) > 255 {
+		return nil, ErrPackageName
+	}
+
+	codec, err := loadPayloadCodec(pc.payloadType)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := codec.Marshal(p.Param)
+	if err != nil {
+		return nil, err
+	}
+
+	length := 3 + len(p.Name) + len(payload)
+	if length > pc.maxFrameSize {
+		return nil, ErrPackageTooLarge
+	}
+
+	dst = binary.BigEndian.AppendUint16(dst, PackageMagic)
+	dst = append(dst, PackageVersion1, pc.payloadType)
+	dst = binary.BigEndian.AppendUint32(dst, uint32(length))
+	dst = binary.BigEndian.AppendUint16(dst, p.Id)
+	dst = append(dst, byte(len(p.Name)))
+	dst = append(dst, p.Name...)
+	return append(dst, payload...), nil
+}
+
+// Decode 解码完整的帧
+func (pc *PackageCodec) Decode(frame []byte) (*Package, error) {
+	if len(frame) < PackageHeaderSize {
+		return nil, ErrDataFormat
+	}
+
+	length, err := pc.parseHeader(frame[:PackageHeaderSize])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(frame)-PackageHeaderSize != length {
+		return nil, ErrDataFormat
+	}
+
+	return pc.decodeBody(frame[3], frame[PackageHeaderSize:])
+}
+
+// parseHeader 校验头部，返回帧体长度
+func (pc *PackageCodec) parseHeader(header []byte) (int, error) {
+	if binary.BigEndian.Uint16(header) != PackageMagic {
+		return 0, ErrPackageMagic
+	}
+
+	if header[2] != PackageVersion1 {
+		return 0, ErrPackageVersion
+	}
+
+	length := binary.BigEndian.Uint32(header[4:])
+	if length > uint32(pc.maxFrameSize) {
+		return 0, ErrPackageTooLarge
+	}
+
+	if length < 3 {
+		return 0, ErrDataFormat
+	}
+
+	return int(length), nil
+}
+
+func (pc *PackageCodec) decodeBody(payloadType uint8, body []byte) (*Package, error) {
+	nameLen := int(body[2])
+	if len(body) < 3+nameLen {
+		return nil, ErrDataFormat
+	}
+
+	codec, err := loadPayloadCodec(payloadType)
+	if err != nil {
+		return nil, err
+	}
+
+	param, err := codec.Unmarshal(body[3+nameLen:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Package{
+		Id:    binary.BigEndian.Uint16(body),
+		Name:  string(body[3 : 3+nameLen]),
+		Param: param,
+	}, nil
+}
+
+// PackageReader 从io.Reader中读取帧，处理半包和粘包
+type PackageReader struct {
+	r      *bufio.Reader
+	codec  *PackageCodec
+	header [PackageHeaderSize]byte
+	body   []byte
+}
+
+// NewPackageReader 实例化PackageReader，codec为nil时使用DefaultPackageCodec
+func NewPackageReader(r io.Reader, codec *PackageCodec) *PackageReader {
+	if codec == nil {
+		codec = DefaultPackageCodec
+	}
+
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	return &PackageReader{
+		r:     br,
+		codec: codec,
+	}
+}
+
+// Read 读取一个Package，连接在帧边界关闭时返回io.EOF，帧不完整时返回io.ErrUnexpectedEOF
+func (pr *PackageReader) Read() (*Package, error) {
+	if _, err := io.ReadFull(pr.r, pr.header[:]); err != nil {
+		return nil, err
+	}
+
+	length, err := pr.codec.parseHeader(pr.header[:])
+	if err != nil {
+		return nil, err
+	}
+
+	if cap(pr.body) < length {
+		pr.body = make([]byte, length)
+	}
+
+	body := pr.body[:length]
+	if _, err = io.ReadFull(pr.r, body); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return pr.codec.decodeBody(pr.header[3], body)
+}
+
+// PackageWriter 向io.Writer中写入帧，可以并发调用
+type PackageWriter struct {
+	mutex sync.Mutex
+	w     io.Writer
+	codec *PackageCodec
+	buf   []byte
+}
+
+// NewPackageWriter 实例化PackageWriter，codec为nil时使用DefaultPackageCodec
+func NewPackageWriter(w io.Writer, codec *PackageCodec) *PackageWriter {
+	if codec == nil {
+		codec = DefaultPackageCodec
+	}
+
+	return &PackageWriter{
+		w:     w,
+		codec: codec,
+	}
+}
+
+// Write 写入一个完整的帧
+func (pw *PackageWriter) Write(p *Package) error {
+	pw.mutex.Lock()
+	defer pw.mutex.Unlock()
+
+	frame, err := pw.codec.AppendEncode(pw.buf[:0], p)
+	if err != nil {
+		return err
+	}
+
+	pw.buf = frame
+	_, err = pw.w.Write(frame)
+	return err
+}
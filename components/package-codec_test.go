@@ -0,0 +1,153 @@
+package components
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/grpc-boot/base/v3/kind"
+)
+
+// oneByteReader 每次只返回1个字节，模拟半包
+type oneByteReader struct {
+	r io.Reader
+}
+
+func (o *oneByteReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return o.r.Read(p[:1])
+}
+
+func TestPackageCodec(t *testing.T) {
+	param := kind.JsonParam{
+		"name":   "grpc-boot",
+		"age":    18,
+		"neg":    -1000,
+		"big":    uint64(1) << 40,
+		"rate":   0.5,
+		"ok":     true,
+		"none":   nil,
+		"tags":   []string{"a", "b"},
+		"nested": kind.JsonParam{"x": int8(-3)},
+	}
+
+	want := kind.JsonParam{
+		"name":   "grpc-boot",
+		"age":    float64(18),
+		"neg":    float64(-1000),
+		"big":    float64(uint64(1) << 40),
+		"rate":   0.5,
+		"ok":     true,
+		"none":   nil,
+		"tags":   []any{"a", "b"},
+		"nested": map[string]any{"x": float64(-3)},
+	}
+
+	for _, payloadType := range []uint8{PayloadJson, PayloadBinary} {
+		codec := NewPackageCodec(WithPayloadType(payloadType))
+		frame, err := codec.Encode(&Package{Id: 7, Name: "user.login", Param: param})
+		if err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+
+		// 解码端不需要知道payload类型
+		pkg, err := DefaultPackageCodec.Decode(frame)
+		if err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+
+		if pkg.Id != 7 || pkg.Name != "user.login" {
+			t.Fatalf("want 7 user.login, got %d %s", pkg.Id, pkg.Name)
+		}
+
+		if !reflect.DeepEqual(map[string]any(pkg.Param), map[string]any(want)) {
+			t.Fatalf("want %v, got %v", want, pkg.Param)
+		}
+
+		if pkg.Param.Int("age") != 18 {
+			t.Fatalf("want 18, got %d", pkg.Param.Int("age"))
+		}
+	}
+}
+
+func TestPackageCodecError(t *testing.T) {
+	codec := NewPackageCodec(WithMaxFrameSize(16))
+	if _, err := codec.Encode(&Package{Name: "too.long.package.name"}); err != ErrPackageTooLarge {
+		t.Fatalf("want ErrPackageTooLarge, got %v", err)
+	}
+
+	frame, _ := DefaultPackageCodec.Encode(&Package{Id: 1, Name: "a", Param: kind.JsonParam{"data": "0123456789abcdef"}})
+	if _, err := codec.Decode(frame); err != ErrPackageTooLarge {
+		t.Fatalf("want ErrPackageTooLarge, got %v", err)
+	}
+
+	bad := append([]byte{}, frame...)
+	bad[0] = 'x'
+	if _, err := DefaultPackageCodec.Decode(bad); err != ErrPackageMagic {
+		t.Fatalf("want ErrPackageMagic, got %v", err)
+	}
+
+	bad = append([]byte{}, frame...)
+	bad[3] = 99
+	if _, err := DefaultPackageCodec.Decode(bad); err != ErrPayloadCodec {
+		t.Fatalf("want ErrPayloadCodec, got %v", err)
+	}
+
+	if _, err := DefaultPackageCodec.Decode(frame[:len(frame)-1]); err != ErrDataFormat {
+		t.Fatalf("want ErrDataFormat, got %v", err)
+	}
+
+	binaryFrame, _ := NewPackageCodec(WithPayloadType(PayloadBinary)).Encode(&Package{Param: kind.JsonParam{"list": []any{1, 2, 3}}})
+	if _, err := DefaultPackageCodec.Decode(binaryFrame[:len(binaryFrame)-1]); err != ErrDataFormat {
+		t.Fatalf("want ErrDataFormat, got %v", err)
+	}
+
+	binaryFrame[4], binaryFrame[5], binaryFrame[6], binaryFrame[7] = 0, 0, 0, byte(len(binaryFrame)-PackageHeaderSize-1)
+	if _, err := DefaultPackageCodec.Decode(binaryFrame[:len(binaryFrame)-1]); err != ErrPayloadFormat {
+		t.Fatalf("want ErrPayloadFormat, got %v", err)
+	}
+
+	if _, err := NewPackageCodec(WithPayloadType(PayloadBinary)).Encode(&Package{Param: kind.JsonParam{"ch": make(chan int)}}); err != ErrPayloadType {
+		t.Fatalf("want ErrPayloadType, got %v", err)
+	}
+}
+
+func TestPackageReaderWriter(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		codec  = NewPackageCodec(WithPayloadType(PayloadBinary))
+		writer = NewPackageWriter(&buf, codec)
+	)
+
+	for i := 1; i <= 3; i++ {
+		if err := writer.Write(&Package{Id: uint16(i), Name: "ping", Param: kind.JsonParam{"seq": i}}); err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+	}
+
+	// 截断最后一帧
+	data := buf.Bytes()[:buf.Len()-1]
+	reader := NewPackageReader(&oneByteReader{r: bytes.NewReader(data)}, codec)
+	for i := 1; i <= 2; i++ {
+		pkg, err := reader.Read()
+		if err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+
+		if int(pkg.Id) != i || pkg.Param.Int("seq") != i {
+			t.Fatalf("want %d, got %d %d", i, pkg.Id, pkg.Param.Int("seq"))
+		}
+	}
+
+	if _, err := reader.Read(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("want io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	reader = NewPackageReader(bytes.NewReader(nil), nil)
+	if _, err := reader.Read(); err != io.EOF {
+		t.Fatalf("want io.EOF, got %v", err)
+	}
+}
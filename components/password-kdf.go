@@ -0,0 +1,163 @@
+package components
+
+import (
+	"crypto"
+	_ "crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+
+	"github.com/grpc-boot/base/v3/utils"
+)
+
+var (
+	ErrScryptParams = errors.New("scrypt N must be power of 2 greater than 1 and r*p < 2^30")
+)
+
+// Pbkdf2 RFC 8018 PBKDF2，伪随机函数为utils.HMacBytes
+func Pbkdf2(password, salt []byte, iterations, keyLen int, hash crypto.Hash) []byte {
+	var (
+		size   = hash.Size()
+		blocks = (keyLen + size - 1) / size
+		dk     = make([]byte, 0, blocks*size)
+		msg    = make([]byte, len(salt)+4)
+	)
+
+	copy(msg, salt)
+	for block := 1; block <= blocks; block++ {
+		binary.BigEndian.PutUint32(msg[len(salt):], uint32(block))
+
+		u := hmacSum(password, msg, hash)
+		t := append([]byte(nil), u...)
+		for n := 1; n < iterations; n++ {
+			u = hmacSum(password, u, hash)
+			for index := range t {
+				t[index] ^= u[index]
+			}
+		}
+
+		dk = append(dk, t...)
+	}
+
+	return dk[:keyLen]
+}
+
+// Scrypt RFC 7914 scrypt，内存占用约128*n*r字节
+func Scrypt(password, salt []byte, n, r, p, keyLen int) ([]byte, error) {
+	if n <= 1 || n&(n-1) != 0 || r < 1 || p < 1 {
+		return nil, ErrScryptParams
+	}
+
+	if uint64(r)*uint64(p) >= 1<<30 || r > math.MaxInt/128/p || r > math.MaxInt/256 || n > math.MaxInt/128/r {
+		return nil, ErrScryptParams
+	}
+
+	var (
+		xy = make([]uint32, 64*r)
+		v  = make([]uint32, 32*n*r)
+		b  = Pbkdf2(password, salt, 1, p*128*r, crypto.SHA256)
+	)
+
+	for index := 0; index < p; index++ {
+		scryptSmix(b[index*128*r:], r, n, v, xy)
+	}
+
+	return Pbkdf2(password, b, 1, keyLen, crypto.SHA256), nil
+}
+
+func hmacSum(key, data []byte, hash crypto.Hash) []byte {
+	// HMacBytes输出合法的hex，解码不会失败
+	sum, _ := utils.HexDecode(utils.HMacBytes(key, data, hash))
+	return sum
+}
+
+// scryptSmix ROMix，b为128*r字节的块
+func scryptSmix(b []byte, r, n int, v, xy []uint32) {
+	var (
+		tmp  [16]uint32
+		size = 32 * r
+		x    = xy
+		y    = xy[size:]
+	)
+
+	for index := 0; index < size; index++ {
+		x[index] = binary.LittleEndian.Uint32(b[index*4:])
+	}
+
+	for index := 0; index < n; index += 2 {
+		copy(v[index*size:], x[:size])
+		scryptBlockMix(&tmp, x, y, r)
+
+		copy(v[(index+1)*size:], y[:size])
+		scryptBlockMix(&tmp, y, x, r)
+	}
+
+	for index := 0; index < n; index += 2 {
+		j := int(scryptInteger(x, r) & uint64(n-1))
+		scryptXor(x, v[j*size:], size)
+		scryptBlockMix(&tmp, x, y, r)
+
+		j = int(scryptInteger(y, r) & uint64(n-1))
+		scryptXor(y, v[j*size:], size)
+		scryptBlockMix(&tmp, y, x, r)
+	}
+
+	for index, value := range x[:size] {
+		binary.LittleEndian.PutUint32(b[index*4:], value)
+	}
+}
+
+func scryptBlockMix(tmp *[16]uint32, in, out []uint32, r int) {
+	copy(tmp[:], in[(2*r-1)*16:(2*r)*16])
+
+	for index := 0; index < 2*r; index += 2 {
+		salsa208(tmp, in[index*16:], out[index*8:])
+		salsa208(tmp, in[index*16+16:], out[index*8+r*16:])
+	}
+}
+
+func scryptInteger(b []uint32, r int) uint64 {
+	j := (2*r - 1) * 16
+	return uint64(b[j]) | uint64(b[j+1])<<32
+}
+
+func scryptXor(dst, src []uint32, size int) {
+	for index := 0; index < size; index++ {
+		dst[index] ^= src[index]
+	}
+}
+
+// salsa208 tmp = Salsa20/8(tmp ^ in)，结果同时写入out
+func salsa208(tmp *[16]uint32, in, out []uint32) {
+	var w [16]uint32
+	for index := range w {
+		w[index] = tmp[index] ^ in[index]
+	}
+
+	x := w
+	for round := 0; round < 8; round += 2 {
+		// 列
+		salsaQuarter(&x, 0, 4, 8, 12)
+		salsaQuarter(&x, 5, 9, 13, 1)
+		salsaQuarter(&x, 10, 14, 2, 6)
+		salsaQuarter(&x, 15, 3, 7, 11)
+		// 行
+		salsaQuarter(&x, 0, 1, 2, 3)
+		salsaQuarter(&x, 5, 6, 7, 4)
+		salsaQuarter(&x, 10, 11, 8, 9)
+		salsaQuarter(&x, 15, 12, 13, 14)
+	}
+
+	for index := range tmp {
+		tmp[index] = x[index] + w[index]
+		out[index] = tmp[index]
+	}
+}
+
+func salsaQuarter(x *[16]uint32, a, b, c, d int) {
+	x[b] ^= bits.RotateLeft32(x[a]+x[d], 7)
+	x[c] ^= bits.RotateLeft32(x[b]+x[a], 9)
+	x[d] ^= bits.RotateLeft32(x[c]+x[b], 13)
+	x[a] ^= bits.RotateLeft32(x[d]+x[c], 18)
+}
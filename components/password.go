@@ -0,0 +1,256 @@
+package components
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	PasswordPbkdf2 = `pbkdf2-sha256`
+	PasswordScrypt = `scrypt`
+)
+
+const (
+	pbkdf2MaxIterations = 10000000
+	scryptMaxCost       = 24
+	scryptMaxBlockSize  = 64
+	scryptMaxParallel   = 64
+	// scryptMaxMemory 128*N*r的上限，防止哈希字符串中的参数耗尽内存
+	scryptMaxMemory    = 256 << 20
+	passwordMinKeySize = 16
+)
+
+var (
+	ErrPasswordMismatch  = errors.New("password mismatch")
+	ErrPasswordHash      = errors.New("invalid password hash")
+	ErrPasswordAlgorithm = errors.New("unsupported password algorithm")
+)
+
+var (
+	passwordEncoding = base64.RawStdEncoding
+)
+
+// PasswordOption 密码哈希选项
+type PasswordOption func(ph *PasswordHasher)
+
+// WithPasswordAlgorithm 新哈希使用的算法，PasswordPbkdf2或PasswordScrypt，默认PasswordPbkdf2
+func WithPasswordAlgorithm(algorithm string) PasswordOption {
+	return func(ph *PasswordHasher) {
+		ph.algorithm = algorithm
+	}
+}
+
+// WithPbkdf2Iterations PBKDF2迭代次数，默认600000
+func WithPbkdf2Iterations(iterations int) PasswordOption {
+	return func(ph *PasswordHasher) {
+		ph.iterations = iterations
+	}
+}
+
+// WithScryptCost scrypt参数，N=2^ln，默认ln=15,r=8,p=1，约占用32MB内存
+func WithScryptCost(ln, r, p int) PasswordOption {
+	return func(ph *PasswordHasher) {
+		ph.cost, ph.blockSize, ph.parallel = ln, r, p
+	}
+}
+
+// WithPasswordSaltSize 盐字节数，默认16
+func WithPasswordSaltSize(size int) PasswordOption {
+	return func(ph *PasswordHasher) {
+		ph.saltSize = size
+	}
+}
+
+// WithPasswordKeySize 哈希字节数，默认32
+func WithPasswordKeySize(size int) PasswordOption {
+	return func(ph *PasswordHasher) {
+		ph.keySize = size
+	}
+}
+
+// PasswordHasher 密码哈希，输出自描述的字符串，格式为：
+// $pbkdf2-sha256$i=600000$salt$hash
+// $scrypt$ln=15,r=8,p=1$salt$hash
+// salt和hash为不带填充的标准base64编码，校验时使用字符串中的参数，修改参数不影响已有哈希
+type PasswordHasher struct {
+	algorithm  string
+	iterations int
+	cost       int
+	blockSize  int
+	parallel   int
+	saltSize   int
+	keySize    int
+}
+
+// NewPasswordHasher 实例化密码哈希
+func NewPasswordHasher(opts ...PasswordOption) (*PasswordHasher, error) {
+	ph := &PasswordHasher{
+		algorithm:  PasswordPbkdf2,
+		iterations: 600000,
+		cost:       15,
+		blockSize:  8,
+		parallel:   1,
+		saltSize:   16,
+		keySize:    32,
+	}
+
+	for _, opt := range opts {
+		opt(ph)
+	}
+
+	if ph.algorithm != PasswordPbkdf2 && ph.algorithm != PasswordScrypt {
+		return nil, ErrPasswordAlgorithm
+	}
+
+	if ph.iterations < 1 || ph.iterations > pbkdf2MaxIterations || ph.saltSize < 8 || ph.keySize < passwordMinKeySize {
+		return nil, ErrOutOfRange
+	}
+
+	if !scryptParamsValid(ph.cost, ph.blockSize, ph.parallel) {
+		return nil, ErrScryptParams
+	}
+
+	return ph, nil
+}
+
+// Hash 使用随机盐生成密码哈希
+func (ph *PasswordHasher) Hash(password string) (string, error) {
+	salt := make([]byte, ph.saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	params := ph.current()
+	key, err := params.derive(password, salt, ph.keySize)
+	if err != nil {
+		return "", err
+	}
+
+	return params.encode(salt, key), nil
+}
+
+// Verify 常量时间比较密码与哈希，不匹配时返回ErrPasswordMismatch，哈希格式错误时返回ErrPasswordHash
+func (ph *PasswordHasher) Verify(password, encoded string) error {
+	params, salt, key, err := parsePasswordHash(encoded)
+	if err != nil {
+		return err
+	}
+
+	derived, err := params.derive(password, salt, len(key))
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare(derived, key) != 1 {
+		return ErrPasswordMismatch
+	}
+
+	return nil
+}
+
+// NeedsRehash 哈希的算法、参数或长度与当前配置不一致时返回true，可在登录校验成功后用明文重新生成
+func (ph *PasswordHasher) NeedsRehash(encoded string) bool {
+	params, salt, key, err := parsePasswordHash(encoded)
+	if err != nil {
+		return true
+	}
+
+	return params != ph.current() || len(salt) != ph.saltSize || len(key) != ph.keySize
+}
+
+func (ph *PasswordHasher) current() passwordParams {
+	if ph.algorithm == PasswordScrypt {
+		return passwordParams{algorithm: PasswordScrypt, cost: ph.cost, blockSize: ph.blockSize, parallel: ph.parallel}
+	}
+
+	return passwordParams{algorithm: PasswordPbkdf2, iterations: ph.iterations}
+}
+
+type passwordParams struct {
+	algorithm  string
+	iterations int
+	cost       int
+	blockSize  int
+	parallel   int
+}
+
+func (pp passwordParams) derive(password string, salt []byte, keySize int) ([]byte, error) {
+	if pp.algorithm == PasswordScrypt {
+		return Scrypt([]byte(password), salt, 1<<pp.cost, pp.blockSize, pp.parallel, keySize)
+	}
+
+	return Pbkdf2([]byte(password), salt, pp.iterations, keySize, crypto.SHA256), nil
+}
+
+func (pp passwordParams) encode(salt, key []byte) string {
+	var buf strings.Builder
+	buf.WriteString("$" + pp.algorithm + "$")
+
+	if pp.algorithm == PasswordScrypt {
+		buf.WriteString("ln=" + strconv.Itoa(pp.cost) + ",r=" + strconv.Itoa(pp.blockSize) + ",p=" + strconv.Itoa(pp.parallel))
+	} else {
+		buf.WriteString("i=" + strconv.Itoa(pp.iterations))
+	}
+
+	buf.WriteString("$" + passwordEncoding.EncodeToString(salt))
+	buf.WriteString("$" + passwordEncoding.EncodeToString(key))
+	return buf.String()
+}
+
+func parsePasswordHash(encoded string) (params passwordParams, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 || parts[0] != "" {
+		return params, nil, nil, ErrPasswordHash
+	}
+
+	params.algorithm = parts[1]
+	values := map[string]int{}
+	for _, pair := range strings.Split(parts[2], ",") {
+		name, value, found := strings.Cut(pair, "=")
+		if !found {
+			return params, nil, nil, ErrPasswordHash
+		}
+
+		if values[name], err = strconv.Atoi(value); err != nil {
+			return params, nil, nil, ErrPasswordHash
+		}
+	}
+
+	switch params.algorithm {
+	case PasswordPbkdf2:
+		params.iterations = values["i"]
+		if len(values) != 1 || params.iterations < 1 || params.iterations > pbkdf2MaxIterations {
+			return params, nil, nil, ErrPasswordHash
+		}
+	case PasswordScrypt:
+		params.cost, params.blockSize, params.parallel = values["ln"], values["r"], values["p"]
+		if len(values) != 3 || !scryptParamsValid(params.cost, params.blockSize, params.parallel) {
+			return params, nil, nil, ErrPasswordHash
+		}
+	default:
+		return params, nil, nil, ErrPasswordAlgorithm
+	}
+
+	if salt, err = passwordEncoding.DecodeString(parts[3]); err != nil || len(salt) == 0 {
+		return params, nil, nil, ErrPasswordHash
+	}
+
+	if key, err = passwordEncoding.DecodeString(parts[4]); err != nil || len(key) < passwordMinKeySize {
+		return params, nil, nil, ErrPasswordHash
+	}
+
+	return params, salt, key, nil
+}
+
+func scryptParamsValid(cost, blockSize, parallel int) bool {
+	if cost < 1 || cost > scryptMaxCost || blockSize < 1 || blockSize > scryptMaxBlockSize || parallel < 1 || parallel > scryptMaxParallel {
+		return false
+	}
+
+	return uint64(128)*(uint64(1)<<cost)*uint64(blockSize) <= scryptMaxMemory
+}
@@ -0,0 +1,116 @@
+package components
+
+import (
+	"crypto"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestPbkdf2(t *testing.T) {
+	// RFC 6070 输入对应的PBKDF2-HMAC-SHA256结果
+	cases := []struct {
+		iterations int
+		want       string
+	}{
+		{1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	}
+
+	for _, c := range cases {
+		if got := hex.EncodeToString(Pbkdf2([]byte("password"), []byte("salt"), c.iterations, 32, crypto.SHA256)); got != c.want {
+			t.Fatalf("want %s, got %s", c.want, got)
+		}
+	}
+}
+
+func TestScrypt(t *testing.T) {
+	// RFC 7914 第12节
+	cases := []struct {
+		password, salt string
+		n, r, p        int
+		want           string
+	}{
+		{"", "", 16, 1, 1, "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906"},
+		{"password", "NaCl", 1024, 8, 16, "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"},
+	}
+
+	for _, c := range cases {
+		key, err := Scrypt([]byte(c.password), []byte(c.salt), c.n, c.r, c.p, 64)
+		if err != nil || hex.EncodeToString(key) != c.want {
+			t.Fatalf("want %s, got %x %v", c.want, key, err)
+		}
+	}
+
+	if _, err := Scrypt([]byte("password"), nil, 1000, 8, 1, 32); err != ErrScryptParams {
+		t.Fatalf("want ErrScryptParams, got %v", err)
+	}
+}
+
+func TestPasswordHasher(t *testing.T) {
+	pbkdf2, err := NewPasswordHasher(WithPbkdf2Iterations(1000))
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	scrypt, err := NewPasswordHasher(WithPasswordAlgorithm(PasswordScrypt), WithScryptCost(10, 8, 1))
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	for prefix, ph := range map[string]*PasswordHasher{"$pbkdf2-sha256$i=1000$": pbkdf2, "$scrypt$ln=10,r=8,p=1$": scrypt} {
+		encoded, err := ph.Hash("correct horse")
+		if err != nil || !strings.HasPrefix(encoded, prefix) {
+			t.Fatalf("want %s, got %s %v", prefix, encoded, err)
+		}
+
+		if err = ph.Verify("correct horse", encoded); err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+
+		if err = ph.Verify("battery staple", encoded); err != ErrPasswordMismatch {
+			t.Fatalf("want ErrPasswordMismatch, got %v", err)
+		}
+
+		if ph.NeedsRehash(encoded) {
+			t.Fatalf("want no rehash for %s", encoded)
+		}
+	}
+
+	// 参数变化后旧哈希仍可校验，但需要重新生成
+	encoded, _ := pbkdf2.Hash("correct horse")
+	upgraded, _ := NewPasswordHasher(WithPbkdf2Iterations(2000))
+	if err = upgraded.Verify("correct horse", encoded); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if !upgraded.NeedsRehash(encoded) || !scrypt.NeedsRehash(encoded) {
+		t.Fatal("want rehash after parameters changed")
+	}
+
+	for _, bad := range []string{"", "plain", "$pbkdf2-sha256$i=0$c2FsdA$aGFzaGhhc2hoYXNoaGFzaA", "$pbkdf2-sha256$i=10$c2FsdA$c2hvcnQ"} {
+		if err = pbkdf2.Verify("correct horse", bad); err != ErrPasswordHash {
+			t.Fatalf("want ErrPasswordHash for %s, got %v", bad, err)
+		}
+	}
+
+	if err = pbkdf2.Verify("correct horse", "$bcrypt$c=10$c2FsdA$aGFzaGhhc2hoYXNoaGFzaA"); err != ErrPasswordAlgorithm {
+		t.Fatalf("want ErrPasswordAlgorithm, got %v", err)
+	}
+
+	// 恶意参数在分配内存之前被拒绝
+	for _, hostile := range []string{
+		"$scrypt$ln=20,r=1073741823,p=1$c2FsdA$aGFzaGhhc2hoYXNoaGFzaA",
+		"$scrypt$ln=10,r=8,p=1073741823$c2FsdA$aGFzaGhhc2hoYXNoaGFzaA",
+		"$scrypt$ln=24,r=64,p=1$c2FsdA$aGFzaGhhc2hoYXNoaGFzaA",
+	} {
+		if err = scrypt.Verify("correct horse", hostile); err != ErrPasswordHash {
+			t.Fatalf("want ErrPasswordHash for %s, got %v", hostile, err)
+		}
+	}
+
+	if _, err = NewPasswordHasher(WithPasswordAlgorithm(PasswordScrypt), WithScryptCost(20, 1024, 1)); err != ErrScryptParams {
+		t.Fatalf("want ErrScryptParams, got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"crypto/x509"
 	"encoding/asn1"
 	"encoding/pem"
+	"errors"
 	"hash"
 )
 
@@ -15,6 +16,11 @@ var (
 	pkcs1Prefix = []byte("BEGIN RSA")
 )
 
+var (
+	ErrPemData = errors.New("invalid pem data")
+	ErrRsaKey  = errors.New("not a rsa key")
+)
+
 // Rsa Rsa
 type Rsa struct {
 	privateKey *rsa.PrivateKey
@@ -43,28 +49,29 @@ func NewRsaWithPkcs8Bytes(public, private []byte) (r *Rsa, err error) {
 
 	if len(private) > 0 {
 		block, _ := pem.Decode(private)
+		if block == nil {
+			return nil, ErrPemData
+		}
+
 		var pKey any
 		pKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
 		if err != nil {
 			return nil, err
 		}
-		priKey = pKey.(*rsa.PrivateKey)
+
+		var ok bool
+		if priKey, ok = pKey.(*rsa.PrivateKey); !ok {
+			return nil, ErrRsaKey
+		}
 	}
 
 	if len(public) > 0 {
-		block, _ := pem.Decode(public)
-		var pKey any
-		pKey, err = x509.ParsePKIXPublicKey(block.Bytes)
-		if err != nil {
+		if pubKey, err = parseRsaPublicKey(public); err != nil {
 			return nil, err
 		}
-		pubKey = pKey.(*rsa.PublicKey)
 	}
 
-	return &Rsa{
-		privateKey: priKey,
-		publicKey:  pubKey,
-	}, nil
+	return NewRsaWithKey(pubKey, priKey), nil
 }
 
 // NewRsaWithPkcs8 pkcs8实例化Rsa
@@ -81,6 +88,10 @@ func NewRsaWithPkcs1Bytes(public, private []byte) (r *Rsa, err error) {
 
 	if len(private) > 0 {
 		block, _ := pem.Decode(private)
+		if block == nil {
+			return nil, ErrPemData
+		}
+
 		priKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
 		if err != nil {
 			return nil, err
@@ -88,19 +99,43 @@ func NewRsaWithPkcs1Bytes(public, private []byte) (r *Rsa, err error) {
 	}
 
 	if len(public) > 0 {
-		block, _ := pem.Decode(public)
-		var pKey any
-		pKey, err = x509.ParsePKIXPublicKey(block.Bytes)
-		if err != nil {
+		if pubKey, err = parseRsaPublicKey(public); err != nil {
 			return nil, err
 		}
-		pubKey = pKey.(*rsa.PublicKey)
+	}
+
+	return NewRsaWithKey(pubKey, priKey), nil
+}
+
+// NewRsaWithKey 使用公钥私钥实例化Rsa，公钥为空时从私钥获取
+func NewRsaWithKey(publicKey *rsa.PublicKey, privateKey *rsa.PrivateKey) *Rsa {
+	if publicKey == nil && privateKey != nil {
+		publicKey = &privateKey.PublicKey
 	}
 
 	return &Rsa{
-		privateKey: priKey,
-		publicKey:  pubKey,
-	}, nil
+		privateKey: privateKey,
+		publicKey:  publicKey,
+	}
+}
+
+func parseRsaPublicKey(public []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(public)
+	if block == nil {
+		return nil, ErrPemData
+	}
+
+	pKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey, ok := pKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, ErrRsaKey
+	}
+
+	return pubKey, nil
 }
 
 // NewRsaWithPkcs1 pkcs1实例化Rsa
@@ -110,7 +145,7 @@ func NewRsaWithPkcs1(publicKey, privateKey string) (r *Rsa, err error) {
 
 // Encrypt 加密
 func (r *Rsa) Encrypt(data []byte) ([]byte, error) {
-	blockLength := r.publicKey.N.BitLen()/8 - 11
+	blockLength := r.publicKey.Size() - 11
 	if len(data) <= blockLength {
 		return rsa.EncryptPKCS1v15(rand.Reader, r.publicKey, data)
 	}
@@ -140,11 +175,7 @@ func (r *Rsa) Encrypt(data []byte) ([]byte, error) {
 
 // Decrypt 解密
 func (r *Rsa) Decrypt(secretData []byte) ([]byte, error) {
-	if r.publicKey == nil {
-		return rsa.DecryptPKCS1v15(rand.Reader, r.privateKey, secretData)
-	}
-
-	blockLength := r.publicKey.N.BitLen() / 8
+	blockLength := r.privateKey.Size()
 	if len(secretData) <= blockLength {
 		return rsa.DecryptPKCS1v15(rand.Reader, r.privateKey, secretData)
 	}
@@ -173,7 +204,7 @@ func (r *Rsa) Decrypt(secretData []byte) ([]byte, error) {
 
 // EncryptOAEP 加密
 func (r *Rsa) EncryptOAEP(data []byte, hash hash.Hash, label []byte) ([]byte, error) {
-	blockLength := r.publicKey.N.BitLen()/8 - 2*hash.Size() - 2
+	blockLength := r.publicKey.Size() - 2*hash.Size() - 2
 	if len(data) <= blockLength {
 		return rsa.EncryptOAEP(hash, rand.Reader, r.publicKey, data, label)
 	}
@@ -203,11 +234,7 @@ func (r *Rsa) EncryptOAEP(data []byte, hash hash.Hash, label []byte) ([]byte, er
 
 // DecryptOAEP 解密
 func (r *Rsa) DecryptOAEP(secretData []byte, hash hash.Hash, label []byte) ([]byte, error) {
-	if r.publicKey == nil {
-		return rsa.DecryptOAEP(hash, rand.Reader, r.privateKey, secretData, label)
-	}
-
-	blockLength := r.publicKey.N.BitLen() / 8
+	blockLength := r.privateKey.Size()
 	if len(secretData) <= blockLength {
 		return rsa.DecryptOAEP(hash, rand.Reader, r.privateKey, secretData, label)
 	}
@@ -236,6 +263,15 @@ func (r *Rsa) DecryptOAEP(secretData []byte, hash hash.Hash, label []byte) ([]by
 
 // Sign 数据签名
 func (r *Rsa) Sign(data []byte, algorithmSign crypto.Hash) ([]byte, error) {
+	if r.privateKey == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	algorithmSign, ok := signHash(algorithmSign, crypto.SHA256)
+	if !ok {
+		return nil, ErrSignAlgorithm
+	}
+
 	hash := algorithmSign.New()
 	hash.Write(data)
 	sign, err := rsa.SignPKCS1v15(rand.Reader, r.privateKey, algorithmSign, hash.Sum(nil))
@@ -247,11 +283,71 @@ func (r *Rsa) Sign(data []byte, algorithmSign crypto.Hash) ([]byte, error) {
 
 // Verify 数据验签
 func (r *Rsa) Verify(data []byte, sign []byte, algorithmSign crypto.Hash) bool {
+	algorithmSign, ok := signHash(algorithmSign, crypto.SHA256)
+	if !ok {
+		return false
+	}
+
 	h := algorithmSign.New()
 	h.Write(data)
 	return rsa.VerifyPKCS1v15(r.publicKey, algorithmSign, h.Sum(nil), sign) == nil
 }
 
+// SignPSS 使用RSASSA-PSS签名，盐长度等于hash长度
+func (r *Rsa) SignPSS(data []byte, algorithmSign crypto.Hash) ([]byte, error) {
+	if r.privateKey == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	algorithmSign, ok := signHash(algorithmSign, crypto.SHA256)
+	if !ok {
+		return nil, ErrSignAlgorithm
+	}
+
+	h := algorithmSign.New()
+	h.Write(data)
+	return rsa.SignPSS(rand.Reader, r.privateKey, algorithmSign, h.Sum(nil), &rsa.PSSOptions{
+		SaltLength: rsa.PSSSaltLengthEqualsHash,
+	})
+}
+
+// VerifyPSS 使用RSASSA-PSS验签
+func (r *Rsa) VerifyPSS(data []byte, sign []byte, algorithmSign crypto.Hash) bool {
+	if r.publicKey == nil {
+		return false
+	}
+
+	algorithmSign, ok := signHash(algorithmSign, crypto.SHA256)
+	if !ok {
+		return false
+	}
+
+	h := algorithmSign.New()
+	h.Write(data)
+	return rsa.VerifyPSS(r.publicKey, algorithmSign, h.Sum(nil), sign, &rsa.PSSOptions{
+		SaltLength: rsa.PSSSaltLengthAuto,
+	}) == nil
+}
+
+func (r *Rsa) Algorithm() string {
+	return AlgorithmRsa
+}
+
+// PublicKey 公钥
+func (r *Rsa) PublicKey() *rsa.PublicKey {
+	return r.publicKey
+}
+
+// PrivateKey 私钥
+func (r *Rsa) PrivateKey() *rsa.PrivateKey {
+	return r.privateKey
+}
+
+// Kid 公钥指纹，RFC 7638 JWK Thumbprint(SHA-256)
+func (r *Rsa) Kid() string {
+	return RsaThumbprint(r.publicKey)
+}
+
 // CreatePkcs1Keys 生成pkcs1格式公钥私钥
 func CreatePkcs1Keys(keyLength int) (privateKey, publicKey string) {
 	rsaPrivateKey, err := rsa.GenerateKey(rand.Reader, keyLength)
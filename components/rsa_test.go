@@ -111,3 +111,76 @@ func TestRsa_Verify(t *testing.T) {
 		t.Fatal("want true got false")
 	}
 }
+
+func TestRsa_VerifyPSS(t *testing.T) {
+	privateKey, publicKey := CreatePkcs8Keys(2048)
+	rsa, _ := NewRsa(publicKey, privateKey)
+	data := []byte("我撒旦法sadfaasdfasdfasdfsfd")
+	sign, err := rsa.SignPSS(data, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !rsa.VerifyPSS(data, sign, crypto.SHA256) {
+		t.Fatal("want true got false")
+	}
+
+	if rsa.VerifyPSS(append(data, '!'), sign, crypto.SHA256) {
+		t.Fatal("want false got true")
+	}
+
+	// 未指定hash时使用SHA256，未链接的hash返回错误而不是panic
+	if sign, err = rsa.SignPSS(data, 0); err != nil || !rsa.VerifyPSS(data, sign, crypto.SHA256) {
+		t.Fatalf("want SHA256 sign, got %v", err)
+	}
+
+	if _, err = rsa.SignPSS(data, crypto.MD4); err != ErrSignAlgorithm {
+		t.Fatalf("want ErrSignAlgorithm, got %v", err)
+	}
+
+	if rsa.VerifyPSS(data, sign, crypto.MD4) || NewRsaWithKey(nil, nil).VerifyPSS(data, sign, crypto.SHA256) {
+		t.Fatal("want false got true")
+	}
+}
+
+func TestRsa_DecryptWithPrivateKeyOnly(t *testing.T) {
+	privateKey, publicKey := CreatePkcs1Keys(2048)
+	encrypter, _ := NewRsa(publicKey, "")
+	decrypter, err := NewRsa("", privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte(strings.Repeat("顿发的sadfaasdfafsfd阿斯asdfadsdfd", 120))
+	secretData, err := encrypter.Encrypt(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pData, err := decrypter.Decrypt(secretData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(pData) != string(data) {
+		t.Fatalf("want %s, got %s", string(data), string(pData))
+	}
+
+	secretData, err = decrypter.EncryptOAEP(data, sha256.New(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pData, err = decrypter.DecryptOAEP(secretData, sha256.New(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(pData) != string(data) {
+		t.Fatalf("want %s, got %s", string(data), string(pData))
+	}
+
+	if _, err = NewRsa("invalid", ""); err != ErrPemData {
+		t.Fatalf("want %v, got %v", ErrPemData, err)
+	}
+}
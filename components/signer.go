@@ -0,0 +1,69 @@
+package components
+
+import (
+	"crypto"
+	"errors"
+	"strings"
+)
+
+const (
+	AlgorithmRsa     = `rsa`
+	AlgorithmEd25519 = `ed25519`
+	AlgorithmEcdsa   = `ecdsa`
+)
+
+var (
+	ErrSignAlgorithm = errors.New("unsupported sign algorithm")
+	ErrNoPrivateKey  = errors.New("private key is empty")
+)
+
+// Signer 数据签名
+type Signer interface {
+	// Sign 使用hash签名，hash为0时使用算法默认值(RSA为SHA256，ECDSA按曲线为SHA256或SHA384)，
+	// 不可用的hash返回ErrSignAlgorithm，Ed25519仅在hash为SHA512时使用Ed25519ph，其他值忽略
+	Sign(data []byte, algorithmSign crypto.Hash) ([]byte, error)
+}
+
+// Verifier 数据验签
+type Verifier interface {
+	// Verify 验证签名，hash的处理与Sign一致，不可用的hash返回false
+	Verify(data []byte, sign []byte, algorithmSign crypto.Hash) bool
+}
+
+// SignVerifier 签名和验签
+type SignVerifier interface {
+	Signer
+	Verifier
+	// Algorithm 算法名称
+	Algorithm() string
+}
+
+// SignerConf 签名配置，公钥私钥为PEM格式，可以只配置其中一个
+type SignerConf struct {
+	Algorithm  string `json:"algorithm" yaml:"algorithm"`
+	PublicKey  string `json:"publicKey" yaml:"publicKey"`
+	PrivateKey string `json:"privateKey" yaml:"privateKey"`
+}
+
+// NewSignVerifier 根据配置实例化签名算法，用于通过配置切换算法
+func NewSignVerifier(conf SignerConf) (SignVerifier, error) {
+	switch strings.ToLower(conf.Algorithm) {
+	case AlgorithmRsa:
+		return NewRsa(conf.PublicKey, conf.PrivateKey)
+	case AlgorithmEd25519:
+		return NewEd25519(conf.PublicKey, conf.PrivateKey)
+	case AlgorithmEcdsa:
+		return NewEcdsa(conf.PublicKey, conf.PrivateKey)
+	default:
+		return nil, ErrSignAlgorithm
+	}
+}
+
+// signHash 校验hash，为0时使用def，未链接的hash返回false，避免hash.New()崩溃
+func signHash(algorithmSign, def crypto.Hash) (crypto.Hash, bool) {
+	if algorithmSign == 0 {
+		return def, true
+	}
+
+	return algorithmSign, algorithmSign.Available()
+}
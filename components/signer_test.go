@@ -0,0 +1,113 @@
+package components
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"testing"
+)
+
+func TestNewSignVerifier(t *testing.T) {
+	rsaPrivate, rsaPublic := CreatePkcs8Keys(2048)
+	edPrivate, edPublic := CreateEd25519Keys()
+	p256Private, p256Public := CreateEcdsaKeys(elliptic.P256())
+	p384Private, p384Public := CreateEcdsaKeys(elliptic.P384())
+
+	cases := []struct {
+		conf SignerConf
+		hash crypto.Hash
+	}{
+		{SignerConf{Algorithm: AlgorithmRsa, PublicKey: rsaPublic, PrivateKey: rsaPrivate}, crypto.SHA256},
+		{SignerConf{Algorithm: AlgorithmEd25519, PublicKey: edPublic, PrivateKey: edPrivate}, 0},
+		{SignerConf{Algorithm: AlgorithmEd25519, PublicKey: edPublic, PrivateKey: edPrivate}, crypto.SHA512},
+		{SignerConf{Algorithm: AlgorithmEcdsa, PublicKey: p256Public, PrivateKey: p256Private}, crypto.SHA256},
+		{SignerConf{Algorithm: "ECDSA", PublicKey: p384Public, PrivateKey: p384Private}, crypto.SHA384},
+	}
+
+	data := []byte("我撒旦法sadfaasdfasdfasdfsfd")
+	for _, c := range cases {
+		signer, err := NewSignVerifier(SignerConf{Algorithm: c.conf.Algorithm, PrivateKey: c.conf.PrivateKey})
+		if err != nil {
+			t.Fatalf("%s want nil, got %v", c.conf.Algorithm, err)
+		}
+
+		verifier, err := NewSignVerifier(SignerConf{Algorithm: c.conf.Algorithm, PublicKey: c.conf.PublicKey})
+		if err != nil {
+			t.Fatalf("%s want nil, got %v", c.conf.Algorithm, err)
+		}
+
+		sign, err := signer.Sign(data, c.hash)
+		if err != nil {
+			t.Fatalf("%s want nil, got %v", c.conf.Algorithm, err)
+		}
+
+		if !verifier.Verify(data, sign, c.hash) {
+			t.Fatalf("%s want true, got false", c.conf.Algorithm)
+		}
+
+		if verifier.Verify(append(data, '!'), sign, c.hash) {
+			t.Fatalf("%s want false, got true", c.conf.Algorithm)
+		}
+
+		if _, err = verifier.Sign(data, c.hash); err != ErrNoPrivateKey {
+			t.Fatalf("%s want %v, got %v", c.conf.Algorithm, ErrNoPrivateKey, err)
+		}
+	}
+
+	if _, err := NewSignVerifier(SignerConf{Algorithm: "dsa"}); err != ErrSignAlgorithm {
+		t.Fatalf("want %v, got %v", ErrSignAlgorithm, err)
+	}
+
+	if _, err := NewEcdsa(edPublic, ""); err != ErrEcdsaKey {
+		t.Fatalf("want %v, got %v", ErrEcdsaKey, err)
+	}
+
+	if private, _ := CreateEcdsaKeys(elliptic.P224()); private != "" {
+		t.Fatalf("want empty, got %s", private)
+	}
+}
+
+func TestSignVerifier_SameHash(t *testing.T) {
+	rsaPrivate, _ := CreatePkcs8Keys(2048)
+	edPrivate, _ := CreateEd25519Keys()
+	p256Private, _ := CreateEcdsaKeys(elliptic.P256())
+	p384Private, _ := CreateEcdsaKeys(elliptic.P384())
+
+	confs := []SignerConf{
+		{Algorithm: AlgorithmRsa, PrivateKey: rsaPrivate},
+		{Algorithm: AlgorithmEd25519, PrivateKey: edPrivate},
+		{Algorithm: AlgorithmEcdsa, PrivateKey: p256Private},
+		{Algorithm: AlgorithmEcdsa, PrivateKey: p384Private},
+	}
+
+	// 通过配置切换算法时调用方使用同样的参数
+	data := []byte("same arguments for every algorithm")
+	for _, hash := range []crypto.Hash{0, crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+		for _, conf := range confs {
+			sv, err := NewSignVerifier(conf)
+			if err != nil {
+				t.Fatalf("%s want nil, got %v", conf.Algorithm, err)
+			}
+
+			sign, err := sv.Sign(data, hash)
+			if err != nil || !sv.Verify(data, sign, hash) {
+				t.Fatalf("%s %v want verified, got %v", conf.Algorithm, hash, err)
+			}
+		}
+	}
+
+	// 未链接的hash不能崩溃
+	for _, conf := range confs {
+		sv, _ := NewSignVerifier(conf)
+		if sv.Algorithm() == AlgorithmEd25519 {
+			continue
+		}
+
+		if _, err := sv.Sign(data, crypto.BLAKE2b_256); err != ErrSignAlgorithm {
+			t.Fatalf("%s want %v, got %v", conf.Algorithm, ErrSignAlgorithm, err)
+		}
+
+		if sv.Verify(data, []byte("sign"), crypto.BLAKE2b_256) {
+			t.Fatalf("%s want false, got true", conf.Algorithm)
+		}
+	}
+}
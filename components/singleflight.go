@@ -0,0 +1,128 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrGroupPanic = errors.New("singleflight call panic")
+)
+
+const (
+	groupSweepMin = 64
+)
+
+type groupCall[V any] struct {
+	done     chan struct{}
+	value    V
+	err      error
+	expireAt int64
+}
+
+// Group 合并同一key的并发调用，只执行一次，ttl>0时成功的结果会缓存ttl时间
+type Group[K comparable, V any] struct {
+	mutex   sync.Mutex
+	calls   map[K]*groupCall[V]
+	cache   map[K]*groupCall[V]
+	ttl     time.Duration
+	sweepAt int
+	now     func() time.Time
+}
+
+// NewGroup 实例化Group，ttl<=0时不缓存结果
+func NewGroup[K comparable, V any](ttl time.Duration) *Group[K, V] {
+	return &Group[K, V]{
+		calls:   make(map[K]*groupCall[V]),
+		cache:   make(map[K]*groupCall[V]),
+		ttl:     ttl,
+		sweepAt: groupSweepMin,
+		now:     time.Now,
+	}
+}
+
+// Do 执行fn并返回结果，同一key同时只有一个fn在执行，其他调用者等待并共享结果，shared表示结果来自其他调用或缓存
+// fn使用的ctx不会随调用者取消，调用者的ctx结束时Do立即返回ctx.Err()，共享的调用继续执行
+func (g *Group[K, V]) Do(ctx context.Context, key K, fn func(ctx context.Context) (V, error)) (value V, shared bool, err error) {
+	g.mutex.Lock()
+	if c, exists := g.cache[key]; exists {
+		if c.expireAt > g.now().UnixNano() {
+			g.mutex.Unlock()
+			return c.value, true, nil
+		}
+		delete(g.cache, key)
+	}
+
+	c, exists := g.calls[key]
+	if !exists {
+		c = &groupCall[V]{done: make(chan struct{})}
+		g.calls[key] = c
+		go g.call(context.WithoutCancel(ctx), key, c, fn)
+	}
+	g.mutex.Unlock()
+
+	select {
+	case <-c.done:
+		return c.value, exists, c.err
+	case <-ctx.Done():
+		return value, exists, ctx.Err()
+	}
+}
+
+// Forget 删除key的缓存结果，正在执行的调用完成后不会写入缓存，之后的Do会重新执行
+func (g *Group[K, V]) Forget(key K) {
+	g.mutex.Lock()
+	delete(g.calls, key)
+	delete(g.cache, key)
+	g.mutex.Unlock()
+}
+
+// Length 正在执行的调用数和缓存的结果数
+func (g *Group[K, V]) Length() (calls, cached int) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return len(g.calls), len(g.cache)
+}
+
+func (g *Group[K, V]) call(ctx context.Context, key K, c *groupCall[V], fn func(ctx context.Context) (V, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = fmt.Errorf("%w: %v", ErrGroupPanic, r)
+		}
+
+		g.mutex.Lock()
+		// 调用期间被Forget时不再写入缓存
+		if g.calls[key] == c {
+			delete(g.calls, key)
+			if g.ttl > 0 && c.err == nil {
+				c.expireAt = g.now().Add(g.ttl).UnixNano()
+				g.store(key, c)
+			}
+		}
+		g.mutex.Unlock()
+
+		close(c.done)
+	}()
+
+	c.value, c.err = fn(ctx)
+}
+
+func (g *Group[K, V]) store(key K, c *groupCall[V]) {
+	// 缓存增长到阈值时清理过期结果
+	if len(g.cache) >= g.sweepAt {
+		now := g.now().UnixNano()
+		for k, item := range g.cache {
+			if item.expireAt <= now {
+				delete(g.cache, k)
+			}
+		}
+
+		g.sweepAt = max(len(g.cache)*2, groupSweepMin)
+	}
+
+	g.cache[key] = c
+}
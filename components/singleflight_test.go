@@ -0,0 +1,138 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+func TestGroup_Do(t *testing.T) {
+	var (
+		g       = NewGroup[string, int](0)
+		calls   atomic.Int32
+		release = make(chan struct{})
+		wg      sync.WaitGroup
+		shared  atomic.Int32
+	)
+
+	fn := func(ctx context.Context) (int, error) {
+		calls.Inc()
+		<-release
+		return 42, nil
+	}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, isShared, err := g.Do(context.Background(), "hot", fn)
+			if err != nil || value != 42 {
+				t.Errorf("want 42, got %d %v", value, err)
+			}
+			if isShared {
+				shared.Inc()
+			}
+		}()
+	}
+
+	for {
+		if n, _ := g.Length(); n == 1 && calls.Load() == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(time.Millisecond * 10)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 || shared.Load() != 9 {
+		t.Fatalf("want 1 call 9 shared, got %d %d", calls.Load(), shared.Load())
+	}
+
+	// ttl=0不缓存
+	if _, isShared, _ := g.Do(context.Background(), "hot", fn); isShared || calls.Load() != 2 {
+		t.Fatalf("want new call, got %v %d", isShared, calls.Load())
+	}
+}
+
+func TestGroup_Cache(t *testing.T) {
+	var (
+		clock = &fakeClock{now: time.Unix(1700000000, 0)}
+		g     = NewGroup[string, int](time.Second)
+		calls int
+	)
+	g.now = clock.Now
+
+	fn := func(ctx context.Context) (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	value, _, _ := g.Do(context.Background(), "k", fn)
+	cached, isShared, _ := g.Do(context.Background(), "k", fn)
+	if value != 1 || cached != 1 || !isShared {
+		t.Fatalf("want cached 1, got %d %d %v", value, cached, isShared)
+	}
+
+	clock.Add(time.Second)
+	if value, _, _ = g.Do(context.Background(), "k", fn); value != 2 {
+		t.Fatalf("want 2, got %d", value)
+	}
+
+	g.Forget("k")
+	if value, _, _ = g.Do(context.Background(), "k", fn); value != 3 {
+		t.Fatalf("want 3, got %d", value)
+	}
+
+	// 错误不缓存
+	failed := errors.New("failed")
+	_, _, err := g.Do(context.Background(), "e", func(ctx context.Context) (int, error) {
+		return 0, failed
+	})
+	if _, cachedNum := g.Length(); err != failed || cachedNum != 1 {
+		t.Fatalf("want failed and 1 cached, got %v %d", err, cachedNum)
+	}
+}
+
+func TestGroup_Cancel(t *testing.T) {
+	var (
+		g       = NewGroup[int, string](time.Minute)
+		release = make(chan struct{})
+		result  = make(chan error, 1)
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_, _, err := g.Do(ctx, 1, func(ctx context.Context) (string, error) {
+			<-release
+			return "ok", ctx.Err()
+		})
+		result <- err
+	}()
+
+	time.Sleep(time.Millisecond * 10)
+	cancel()
+	if err := <-result; err != context.Canceled {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+
+	// 调用者取消不影响共享调用
+	close(release)
+	value, isShared, err := g.Do(context.Background(), 1, func(ctx context.Context) (string, error) {
+		return "new", nil
+	})
+	if err != nil || value != "ok" || !isShared {
+		t.Fatalf("want shared ok, got %s %v %v", value, isShared, err)
+	}
+
+	_, _, err = g.Do(context.Background(), 2, func(ctx context.Context) (string, error) {
+		panic("boom")
+	})
+	if !errors.Is(err, ErrGroupPanic) {
+		t.Fatalf("want ErrGroupPanic, got %v", err)
+	}
+}
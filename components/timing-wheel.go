@@ -0,0 +1,295 @@
+package components
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grpc-boot/base/v3/gopool"
+	"github.com/grpc-boot/base/v3/logger"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrWheelOptions = errors.New("timing wheel tick, size and levels must be positive")
+)
+
+// WheelOption 时间轮选项
+type WheelOption func(tw *TimingWheel)
+
+// WithWheelTick 最小刻度，默认10ms，定时精度不高于tick
+func WithWheelTick(tick time.Duration) WheelOption {
+	return func(tw *TimingWheel) {
+		tw.tick = tick
+	}
+}
+
+// WithWheelSize 每层槽数和层数，默认64槽4层，最大延迟为tick*size^levels，超出的定时器会在最高层多次轮转
+func WithWheelSize(size, levels int) WheelOption {
+	return func(tw *TimingWheel) {
+		tw.size = uint64(size)
+		tw.levels = levels
+	}
+}
+
+// WheelTimer 时间轮定时器句柄
+type WheelTimer struct {
+	wheel  *TimingWheel
+	fn     func()
+	expire uint64
+	slot   *wheelSlot
+	prev   *WheelTimer
+	next   *WheelTimer
+}
+
+// Cancel 取消定时器，返回定时器是否处于等待状态
+func (wt *WheelTimer) Cancel() bool {
+	tw := wt.wheel
+
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+
+	return tw.remove(wt)
+}
+
+// Reset 重新设置定时器在delay后执行，已执行或已取消的定时器会重新加入，返回重置前是否处于等待状态
+func (wt *WheelTimer) Reset(delay time.Duration) bool {
+	tw := wt.wheel
+
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+
+	pending := tw.remove(wt)
+	wt.expire = tw.current + tw.ticks(delay)
+	tw.insert(wt)
+	return pending
+}
+
+type wheelSlot struct {
+	head WheelTimer
+}
+
+func (ws *wheelSlot) init() {
+	ws.head.prev = &ws.head
+	ws.head.next = &ws.head
+}
+
+func (ws *wheelSlot) push(wt *WheelTimer) {
+	wt.slot = ws
+	wt.prev = ws.head.prev
+	wt.next = &ws.head
+	ws.head.prev.next = wt
+	ws.head.prev = wt
+}
+
+// take 取出槽内所有定时器
+func (ws *wheelSlot) take() (list []*WheelTimer) {
+	for wt := ws.head.next; wt != &ws.head; {
+		next := wt.next
+		wt.slot, wt.prev, wt.next = nil, nil, nil
+		list = append(list, wt)
+		wt = next
+	}
+
+	ws.init()
+	return
+}
+
+// TimingWheel 分层时间轮，适合大量连接的心跳、空闲超时和ack超时，回调在gopool.Pool中执行
+type TimingWheel struct {
+	mutex   sync.Mutex
+	pool    *gopool.Pool
+	tick    time.Duration
+	size    uint64
+	levels  int
+	spans   []uint64
+	slots   [][]wheelSlot
+	current uint64
+	length  int
+	start   time.Time
+	started bool
+	done    chan struct{}
+	once    sync.Once
+	now     func() time.Time
+}
+
+// NewTimingWheel 实例化时间轮，需要调用Start开始转动
+func NewTimingWheel(pool *gopool.Pool, opts ...WheelOption) (*TimingWheel, error) {
+	tw := &TimingWheel{
+		pool:   pool,
+		tick:   time.Millisecond * 10,
+		size:   64,
+		levels: 4,
+		done:   make(chan struct{}),
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(tw)
+	}
+
+	if tw.tick <= 0 || tw.size < 2 || tw.levels < 1 {
+		return nil, ErrWheelOptions
+	}
+
+	tw.spans = make([]uint64, tw.levels+1)
+	tw.slots = make([][]wheelSlot, tw.levels)
+	tw.spans[0] = 1
+	for level := 0; level < tw.levels; level++ {
+		tw.spans[level+1] = tw.spans[level] * tw.size
+		tw.slots[level] = make([]wheelSlot, tw.size)
+		for index := range tw.slots[level] {
+			tw.slots[level][index].init()
+		}
+	}
+
+	return tw, nil
+}
+
+// Add 添加定时器，delay后在pool中执行fn
+func (tw *TimingWheel) Add(delay time.Duration, fn func()) *WheelTimer {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+
+	wt := &WheelTimer{
+		wheel:  tw,
+		fn:     fn,
+		expire: tw.current + tw.ticks(delay),
+	}
+
+	tw.insert(wt)
+	return wt
+}
+
+// Length 等待中的定时器数量
+func (tw *TimingWheel) Length() int {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+
+	return tw.length
+}
+
+// Start 开始转动，重复调用或Stop之后调用无效
+func (tw *TimingWheel) Start() {
+	tw.mutex.Lock()
+	defer tw.mutex.Unlock()
+
+	select {
+	case <-tw.done:
+		return
+	default:
+	}
+
+	if tw.started {
+		return
+	}
+
+	tw.started = true
+	tw.start = tw.now()
+	go tw.run()
+}
+
+// Stop 停止转动，未执行的定时器不会再执行，可直接用于grace.Graceful.OnShutdown
+func (tw *TimingWheel) Stop() error {
+	tw.once.Do(func() {
+		close(tw.done)
+	})
+
+	return nil
+}
+
+func (tw *TimingWheel) run() {
+	ticker := time.NewTicker(tw.tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// 根据实际经过的时间追赶，避免ticker丢失刻度导致定时器延后
+			tw.advanceTo(uint64(tw.now().Sub(tw.start) / tw.tick))
+		case <-tw.done:
+			return
+		}
+	}
+}
+
+// advanceTo 前进到target刻度，到期的定时器在释放锁后提交到pool
+func (tw *TimingWheel) advanceTo(target uint64) {
+	var expired []*WheelTimer
+
+	tw.mutex.Lock()
+	for tw.current < target {
+		tw.current++
+
+		// 由高到低将到达的上层槽降级
+		for level := tw.levels - 1; level > 0; level-- {
+			if tw.current%tw.spans[level] != 0 {
+				continue
+			}
+
+			slot := &tw.slots[level][(tw.current/tw.spans[level])%tw.size]
+			for _, wt := range slot.take() {
+				tw.length--
+				if !tw.insert(wt) {
+					expired = append(expired, wt)
+				}
+			}
+		}
+
+		for _, wt := range tw.slots[0][tw.current%tw.size].take() {
+			tw.length--
+			expired = append(expired, wt)
+		}
+	}
+	tw.mutex.Unlock()
+
+	for _, wt := range expired {
+		if err := tw.pool.Submit(wt.fn); err != nil {
+			logger.Error("timing wheel submit failed",
+				zap.NamedError("Error", err),
+			)
+		}
+	}
+}
+
+// insert 定时器放入对应的槽，已到期时返回false
+func (tw *TimingWheel) insert(wt *WheelTimer) bool {
+	if wt.expire <= tw.current {
+		return false
+	}
+
+	var (
+		delta = wt.expire - tw.current
+		level = 0
+	)
+
+	for level < tw.levels-1 && delta >= tw.spans[level+1] {
+		level++
+	}
+
+	tw.slots[level][(wt.expire/tw.spans[level])%tw.size].push(wt)
+	tw.length++
+	return true
+}
+
+func (tw *TimingWheel) remove(wt *WheelTimer) bool {
+	if wt.slot == nil {
+		return false
+	}
+
+	wt.prev.next = wt.next
+	wt.next.prev = wt.prev
+	wt.slot, wt.prev, wt.next = nil, nil, nil
+	tw.length--
+	return true
+}
+
+// ticks 延迟换算为刻度数，向上取整且至少为1
+func (tw *TimingWheel) ticks(delay time.Duration) uint64 {
+	if delay <= tw.tick {
+		return 1
+	}
+
+	return uint64((delay + tw.tick - 1) / tw.tick)
+}
@@ -0,0 +1,127 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grpc-boot/base/v3/gopool"
+
+	"go.uber.org/atomic"
+)
+
+func TestTimingWheel_Advance(t *testing.T) {
+	pool, _ := gopool.NewPool(8)
+	tw, err := NewTimingWheel(pool, WithWheelTick(time.Millisecond), WithWheelSize(8, 3))
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	var (
+		fired   = make(chan uint64, 16)
+		delays  = []uint64{1, 7, 8, 9, 63, 64, 65, 100, 511, 512, 513, 2000}
+		pending = map[uint64]bool{}
+	)
+
+	// 覆盖各层以及超出最大范围的延迟
+	for _, delay := range delays {
+		delay := delay
+		pending[delay] = true
+		tw.Add(time.Duration(delay)*time.Millisecond, func() {
+			fired <- delay
+		})
+	}
+
+	if tw.Length() != len(delays) {
+		t.Fatalf("want %d, got %d", len(delays), tw.Length())
+	}
+
+	for tick := uint64(1); tick <= 2000; tick++ {
+		tw.advanceTo(tick)
+		if !pending[tick] {
+			continue
+		}
+
+		if delay := <-fired; delay != tick {
+			t.Fatalf("want fired at %d, got %d", tick, delay)
+		}
+	}
+
+	if tw.Length() != 0 {
+		t.Fatalf("want 0, got %d", tw.Length())
+	}
+}
+
+func TestTimingWheel_CancelReset(t *testing.T) {
+	pool, _ := gopool.NewPool(4)
+	tw, _ := NewTimingWheel(pool, WithWheelTick(time.Millisecond), WithWheelSize(16, 2))
+
+	var count atomic.Int32
+	canceled := tw.Add(time.Millisecond*10, func() { count.Add(100) })
+	if !canceled.Cancel() || canceled.Cancel() {
+		t.Fatal("want first cancel true, second false")
+	}
+
+	done := make(chan struct{})
+	reset := tw.Add(time.Millisecond*10, func() {
+		count.Inc()
+		close(done)
+	})
+
+	// 模拟连接活跃时延后空闲超时
+	tw.advanceTo(8)
+	if !reset.Reset(time.Millisecond * 30) {
+		t.Fatal("want pending timer")
+	}
+
+	tw.advanceTo(37)
+	if count.Load() != 0 {
+		t.Fatalf("want 0, got %d", count.Load())
+	}
+
+	tw.advanceTo(38)
+	<-done
+	if count.Load() != 1 {
+		t.Fatalf("want 1, got %d", count.Load())
+	}
+
+	if reset.Reset(time.Millisecond) {
+		t.Fatal("want fired timer not pending")
+	}
+	if tw.Length() != 1 {
+		t.Fatalf("want 1, got %d", tw.Length())
+	}
+}
+
+func TestTimingWheel_Start(t *testing.T) {
+	pool, _ := gopool.NewPool(4)
+	tw, _ := NewTimingWheel(pool, WithWheelTick(time.Millisecond*5))
+	tw.Start()
+	defer tw.Stop()
+
+	start := time.Now()
+	done := make(chan time.Duration, 1)
+	tw.Add(time.Millisecond*50, func() {
+		done <- time.Since(start)
+	})
+
+	select {
+	case elapsed := <-done:
+		if elapsed < time.Millisecond*45 {
+			t.Fatalf("want >= 45ms, got %v", elapsed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("want timer fired")
+	}
+
+	// 重复调用Start不会重置起始时间
+	first := tw.start
+	time.Sleep(time.Millisecond)
+	tw.Start()
+	if !tw.start.Equal(first) {
+		t.Fatalf("want %v, got %v", first, tw.start)
+	}
+
+	if _, err := NewTimingWheel(pool, WithWheelSize(1, 1)); err != ErrWheelOptions {
+		t.Fatalf("want ErrWheelOptions, got %v", err)
+	}
+}
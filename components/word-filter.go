@@ -0,0 +1,413 @@
+package components
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/grpc-boot/base/v3/kind/trie"
+	"github.com/grpc-boot/base/v3/logger"
+	"github.com/grpc-boot/base/v3/utils"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+const (
+	WordAllowFile   = `allow`
+	WordDefaultMask = `*`
+)
+
+var (
+	ErrWordDictEmpty = errors.New("word dict is empty")
+)
+
+// WordCategory 一类敏感词，Replace为单个字符时逐字替换，否则整体替换为Replace，为空时使用WordDefaultMask
+type WordCategory struct {
+	Replace string   `json:"replace" yaml:"replace"`
+	Words   []string `json:"words" yaml:"words"`
+}
+
+// WordDict 敏感词词典，Allow中的词包含的敏感词不会被匹配
+type WordDict struct {
+	Categories map[string]*WordCategory `json:"categories" yaml:"categories"`
+	Allow      []string                 `json:"allow" yaml:"allow"`
+}
+
+// WordMatch 匹配结果，Start、End为原文中的字节位置
+type WordMatch struct {
+	Category string
+	Word     string
+	Start    int
+	End      int
+}
+
+// WordLoader 加载敏感词词典
+type WordLoader func() (*WordDict, error)
+
+// YamlWordLoader 从Yaml文件加载词典
+func YamlWordLoader(filePath string) WordLoader {
+	return func() (*WordDict, error) {
+		dict := &WordDict{}
+		if err := utils.YamlUnmarshalFile(filePath, dict); err != nil {
+			return nil, err
+		}
+		return dict, nil
+	}
+}
+
+// JsonWordLoader 从Json文件加载词典
+func JsonWordLoader(filePath string) WordLoader {
+	return func() (*WordDict, error) {
+		dict := &WordDict{}
+		if err := utils.JsonUnmarshalFile(filePath, dict); err != nil {
+			return nil, err
+		}
+		return dict, nil
+	}
+}
+
+// TextWordLoader 从目录加载文本词典，文件名为分类，每行一个词，#开头的行为注释，allow.txt为白名单
+func TextWordLoader(dir string) WordLoader {
+	return TextWordFSLoader(os.DirFS(dirOrCurrent(dir)), ".")
+}
+
+// TextWordFSLoader 从fs.FS中dir目录加载文本词典
+func TextWordFSLoader(fsys fs.FS, dir string) WordLoader {
+	return func() (*WordDict, error) {
+		fileList, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		dict := &WordDict{Categories: make(map[string]*WordCategory, len(fileList))}
+		for _, fi := range fileList {
+			if fi.IsDir() || path.Ext(fi.Name()) != ".txt" {
+				continue
+			}
+
+			data, err := fs.ReadFile(fsys, path.Join(dir, fi.Name()))
+			if err != nil {
+				return nil, err
+			}
+
+			words := readWordLines(data)
+			name := strings.TrimSuffix(fi.Name(), ".txt")
+			if name == WordAllowFile {
+				dict.Allow = append(dict.Allow, words...)
+				continue
+			}
+
+			dict.Categories[name] = &WordCategory{Words: words}
+		}
+
+		return dict, nil
+	}
+}
+
+func readWordLines(data []byte) (words []string) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+
+	return
+}
+
+type wordCategory struct {
+	name    string
+	replace []rune
+	set     *trie.Set
+}
+
+type wordDict struct {
+	categories []*wordCategory
+	allow      *trie.Set
+}
+
+// WordFilter 敏感词过滤，输入会做全角转半角、大小写折叠，并跳过词中间的空白、标点和符号，热加载时原子替换词典，读取不加锁
+type WordFilter struct {
+	loader    WordLoader
+	current   atomic.Pointer[wordDict]
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewWordFilter 实例化敏感词过滤
+func NewWordFilter(loader WordLoader) (*WordFilter, error) {
+	wf := &WordFilter{
+		loader: loader,
+		done:   make(chan struct{}),
+	}
+
+	if err := wf.Reload(); err != nil {
+		return nil, err
+	}
+
+	return wf, nil
+}
+
+// Reload 重新加载词典，加载失败时保留原词典
+func (wf *WordFilter) Reload() error {
+	dict, err := wf.loader()
+	if err != nil {
+		return err
+	}
+
+	if dict == nil || len(dict.Categories) < 1 {
+		return ErrWordDictEmpty
+	}
+
+	compiled := &wordDict{
+		categories: make([]*wordCategory, 0, len(dict.Categories)),
+		allow:      trie.NewTrieSet(),
+	}
+
+	for name, category := range dict.Categories {
+		if category == nil {
+			continue
+		}
+
+		wc := &wordCategory{
+			name:    name,
+			replace: []rune(category.Replace),
+			set:     trie.NewTrieSet(),
+		}
+
+		if len(wc.replace) == 0 {
+			wc.replace = []rune(WordDefaultMask)
+		}
+
+		for _, word := range category.Words {
+			if word = NormalizeWord(word); word != "" {
+				wc.set.Add(word)
+			}
+		}
+
+		compiled.categories = append(compiled.categories, wc)
+	}
+
+	// 分类按名称排序，保证同一位置的匹配顺序稳定
+	sort.Slice(compiled.categories, func(i, j int) bool {
+		return compiled.categories[i].name < compiled.categories[j].name
+	})
+
+	for _, word := range dict.Allow {
+		if word = NormalizeWord(word); word != "" {
+			compiled.allow.Add(word)
+		}
+	}
+
+	wf.current.Store(compiled)
+	return nil
+}
+
+// Watch 每隔interval检查词典文件或目录的大小和修改时间，有变化时重新加载，调用Close停止
+func (wf *WordFilter) Watch(filePath string, interval time.Duration) {
+	last, _ := pathSignature(filePath)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-wf.done:
+				return
+			case <-ticker.C:
+			}
+
+			sign, err := pathSignature(filePath)
+			if err != nil {
+				logger.Error("word filter watch failed",
+					zap.String("Path", filePath),
+					zap.NamedError("Error", err),
+				)
+				continue
+			}
+
+			if sign == last {
+				continue
+			}
+
+			if err = wf.Reload(); err != nil {
+				logger.Error("word filter reload failed",
+					zap.String("Path", filePath),
+					zap.NamedError("Error", err),
+				)
+				continue
+			}
+
+			last = sign
+		}
+	}()
+}
+
+// Close 停止Watch
+func (wf *WordFilter) Close() {
+	wf.closeOnce.Do(func() {
+		close(wf.done)
+	})
+}
+
+// Contains 是否包含敏感词
+func (wf *WordFilter) Contains(text string) bool {
+	found := false
+	wf.scan(text, func(category *wordCategory, start, end int) bool {
+		found = true
+		return false
+	})
+
+	return found
+}
+
+// Match 返回所有匹配，每个位置每个分类取最长的词，按位置排序
+func (wf *WordFilter) Match(text string) (matches []WordMatch) {
+	offsets := runeOffsets(text)
+	wf.scan(text, func(category *wordCategory, start, end int) bool {
+		matches = append(matches, WordMatch{
+			Category: category.name,
+			Word:     text[offsets[start]:offsets[end]],
+			Start:    offsets[start],
+			End:      offsets[end],
+		})
+		return true
+	})
+
+	return matches
+}
+
+// Replace 按分类的替换规则替换敏感词，重叠的匹配以先出现的为准
+func (wf *WordFilter) Replace(text string) string {
+	var (
+		data   = []rune(text)
+		result = make([]rune, 0, len(data))
+		cursor = 0
+	)
+
+	wf.scan(text, func(category *wordCategory, start, end int) bool {
+		if start < cursor {
+			return true
+		}
+
+		result = append(result, data[cursor:start]...)
+		if len(category.replace) == 1 {
+			for index := start; index < end; index++ {
+				result = append(result, category.replace[0])
+			}
+		} else {
+			result = append(result, category.replace...)
+		}
+
+		cursor = end
+		return true
+	})
+
+	if cursor == 0 {
+		return text
+	}
+
+	result = append(result, data[cursor:]...)
+	return string(result)
+}
+
+// scan 遍历匹配，start、end为字符位置，handler返回false时停止
+func (wf *WordFilter) scan(text string, handler func(category *wordCategory, start, end int) bool) {
+	var (
+		dict     = wf.current.Load()
+		data     = normalizeRunes(text)
+		allowEnd = -1
+	)
+
+	for start := 0; start < len(data); start++ {
+		if isWordNoise(data[start]) {
+			continue
+		}
+
+		// 被白名单词覆盖的位置不匹配
+		if end := dict.allow.MatchAt(data, start, isWordNoise); end > allowEnd {
+			allowEnd = end
+		}
+
+		for _, category := range dict.categories {
+			end := category.set.MatchAt(data, start, isWordNoise)
+			if end < 0 || end <= allowEnd {
+				continue
+			}
+
+			if !handler(category, start, end) {
+				return
+			}
+		}
+	}
+}
+
+// NormalizeWord 全角转半角、大小写折叠并去掉空白、标点和符号
+func NormalizeWord(word string) string {
+	var sb strings.Builder
+	for _, r := range normalizeRunes(word) {
+		if !isWordNoise(r) {
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
+
+// normalizeRunes 逐字符转换，转换后字符数不变，便于映射回原文位置
+func normalizeRunes(text string) []rune {
+	data := []rune(text)
+	for index, r := range data {
+		switch {
+		case r == 0x3000:
+			r = ' '
+		case r >= 0xFF01 && r <= 0xFF5E:
+			r -= 0xFEE0
+		}
+
+		data[index] = unicode.ToLower(r)
+	}
+
+	return data
+}
+
+func isWordNoise(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// runeOffsets 每个字符在原文中的字节位置，最后一项为len(text)
+func runeOffsets(text string) []int {
+	offsets := make([]int, 0, utf8.RuneCountInString(text)+1)
+	for index := range text {
+		offsets = append(offsets, index)
+	}
+
+	return append(offsets, len(text))
+}
+
+func pathSignature(filePath string) (string, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	if info.IsDir() {
+		return dirSignature(filePath)
+	}
+
+	return strconv.FormatInt(info.Size(), 10) + ":" + strconv.FormatInt(info.ModTime().UnixNano(), 10), nil
+}
@@ -0,0 +1,111 @@
+package components
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestWordFilter_Match(t *testing.T) {
+	wf, err := NewWordFilter(func() (*WordDict, error) {
+		return &WordDict{
+			Categories: map[string]*WordCategory{
+				"ads":   {Replace: "[广告]", Words: []string{"加微信", "VX"}},
+				"abuse": {Words: []string{"笨蛋", "ass"}},
+			},
+			Allow: []string{"class", "assess"},
+		}, nil
+	})
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	text := "你这个笨-蛋，加 微 信ＶＸ123，pass the class"
+	matches := wf.Match(text)
+	if len(matches) != 4 {
+		t.Fatalf("want 4 matches, got %+v", matches)
+	}
+
+	want := []WordMatch{
+		{Category: "abuse", Word: "笨-蛋"},
+		{Category: "ads", Word: "加 微 信"},
+		{Category: "ads", Word: "ＶＸ"},
+		{Category: "abuse", Word: "ass"},
+	}
+
+	for index, match := range matches {
+		if match.Category != want[index].Category || match.Word != want[index].Word || text[match.Start:match.End] != match.Word {
+			t.Fatalf("want %+v, got %+v", want[index], match)
+		}
+	}
+
+	if replaced := wf.Replace(text); replaced != "你这个***，[广告][广告]123，p*** the class" {
+		t.Fatalf("want replaced, got %s", replaced)
+	}
+
+	if wf.Contains("assess the class") {
+		t.Fatal("want allowed words not matched")
+	}
+
+	if !wf.Contains("ASS") || wf.Contains("hello") {
+		t.Fatal("want ASS matched, hello not matched")
+	}
+}
+
+func TestWordFilter_Loader(t *testing.T) {
+	fsys := fstest.MapFS{
+		"politics.txt": {Data: []byte("# 注释\n敏感词\n\n")},
+		"allow.txt":    {Data: []byte("不敏感词\n")},
+		"readme.md":    {Data: []byte("ignored")},
+	}
+
+	wf, err := NewWordFilter(TextWordFSLoader(fsys, "."))
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if !wf.Contains("这是敏感词") || wf.Contains("这是不敏感词") {
+		t.Fatal("want text dict loaded")
+	}
+
+	if _, err = NewWordFilter(TextWordFSLoader(fstest.MapFS{}, ".")); err != ErrWordDictEmpty {
+		t.Fatalf("want ErrWordDictEmpty, got %v", err)
+	}
+
+	var (
+		dir  = t.TempDir()
+		file = filepath.Join(dir, "words.yml")
+	)
+
+	if err = os.WriteFile(file, []byte("categories:\n  ads:\n    words: [广告]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wf, err = NewWordFilter(YamlWordLoader(file))
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	wf.Watch(file, time.Millisecond*10)
+	defer wf.Close()
+
+	if !wf.Contains("打广告") || wf.Contains("刷单") {
+		t.Fatal("want yaml dict loaded")
+	}
+
+	// 热加载
+	if err = os.WriteFile(file, []byte("categories:\n  ads:\n    words: [广告, 刷单]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.Chtimes(file, time.Now(), time.Now().Add(time.Second))
+
+	deadline := time.Now().Add(time.Second)
+	for !wf.Contains("刷单") {
+		if time.Now().After(deadline) {
+			t.Fatal("want dict reloaded")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
@@ -3,10 +3,17 @@ package elasticsearch
 import (
 	"context"
 	"golang.org/x/exp/rand"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/grpc-boot/base/v3/components"
 	"github.com/grpc-boot/base/v3/elasticsearch/result"
+	"github.com/grpc-boot/base/v3/kind"
+	"github.com/grpc-boot/base/v3/retry"
+
+	"go.uber.org/atomic"
 )
 
 var (
@@ -232,3 +239,74 @@ func TestPool_DocIndex(t *testing.T) {
 	res, err = p.DocFieldIncr(ctx, index, res.Id, "id", 10)
 	t.Logf("res: %+v", res)
 }
+
+type otherServer uint32
+
+func (s otherServer) HashCode() uint32 {
+	return uint32(s)
+}
+
+func TestPool_SetPicker(t *testing.T) {
+	var hits [2]atomic.Int32
+	handler := func(index int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			hits[index].Inc()
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}
+
+	first := httptest.NewServer(handler(0))
+	defer first.Close()
+	second := httptest.NewServer(handler(1))
+	defer second.Close()
+
+	pool := NewPool(DefaultOption())
+	pool.SetPicker(components.NewSmoothRoundRobin(NewNodes(first.URL, second.URL+"/")))
+
+	for i := 0; i < 4; i++ {
+		resp, err := pool.Request(context.Background(), http.MethodGet, "_cluster/health", nil, nil)
+		if err != nil || !resp.Is(http.StatusOK) {
+			t.Fatalf("want 200, got %v %v", resp, err)
+		}
+	}
+
+	if hits[0].Load() != 2 || hits[1].Load() != 2 {
+		t.Fatalf("want 2 2, got %d %d", hits[0].Load(), hits[1].Load())
+	}
+
+	// picker中不是*Node时返回错误，不回退到BaseUrl
+	pool.SetPicker(components.NewSmoothRoundRobin([]kind.CanHash{otherServer(1)}))
+	if _, err := pool.Request(context.Background(), http.MethodGet, "_cluster/health", nil, nil); err != ErrNodeType {
+		t.Fatalf("want ErrNodeType, got %v", err)
+	}
+}
+
+func TestPool_PickerRetry(t *testing.T) {
+	var hits [2]atomic.Int32
+	failed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits[0].Inc()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failed.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits[1].Inc()
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer healthy.Close()
+
+	pool := NewPool(DefaultOption())
+	pool.SetRetry(retry.New(retry.WithMaxAttempts(3), retry.WithBackoff(retry.Constant(0))))
+	pool.SetPicker(components.NewSmoothRoundRobin(NewNodes(failed.URL, healthy.URL), components.WithMaxFails(1)))
+
+	// 重试时重新选择节点，失败的节点被摘除后不再使用
+	for i := 0; i < 4; i++ {
+		resp, err := pool.Request(context.Background(), http.MethodGet, "_cluster/health", nil, nil)
+		if err != nil || !resp.Is(http.StatusOK) {
+			t.Fatalf("want 200, got %v %v", resp, err)
+		}
+	}
+
+	if hits[0].Load() != 1 || hits[1].Load() != 4 {
+		t.Fatalf("want 1 4, got %d %d", hits[0].Load(), hits[1].Load())
+	}
+}
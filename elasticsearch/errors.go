@@ -7,4 +7,6 @@ var (
 	ErrSetterEmpty     = errors.New("setter is empty")
 	ErrPropertiesEmpty = errors.New("properties is empty")
 	ErrIndexNotExists  = errors.New("no such index")
+	ErrNodeStatus      = errors.New("elasticsearch node error status")
+	ErrNodeType        = errors.New("picker server must be *elasticsearch.Node")
 )
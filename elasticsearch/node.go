@@ -0,0 +1,48 @@
+package elasticsearch
+
+import (
+	"strings"
+
+	"github.com/grpc-boot/base/v3/kind"
+	"github.com/grpc-boot/base/v3/utils"
+)
+
+// Node 集群节点，配合components.Picker在多个节点间负载均衡
+type Node struct {
+	url    string
+	weight int
+}
+
+// NewNode 实例化节点，weight<=0时为1
+func NewNode(url string, weight int) *Node {
+	if weight <= 0 {
+		weight = 1
+	}
+
+	return &Node{
+		url:    strings.TrimSuffix(url, "/"),
+		weight: weight,
+	}
+}
+
+// NewNodes 根据url列表实例化权重为1的节点
+func NewNodes(urls ...string) []kind.CanHash {
+	nodes := make([]kind.CanHash, len(urls))
+	for index, url := range urls {
+		nodes[index] = NewNode(url, 1)
+	}
+
+	return nodes
+}
+
+func (n *Node) Url() string {
+	return n.url
+}
+
+func (n *Node) Weight() int {
+	return n.weight
+}
+
+func (n *Node) HashCode() uint32 {
+	return kind.Uint32Hash(utils.String2Bytes(n.url))
+}
@@ -3,12 +3,15 @@ package elasticsearch
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/grpc-boot/base/v3/components"
 	"github.com/grpc-boot/base/v3/elasticsearch/result"
 	"github.com/grpc-boot/base/v3/http_client"
+	"github.com/grpc-boot/base/v3/retry"
 	"github.com/grpc-boot/base/v3/utils"
 )
 
@@ -16,6 +19,7 @@ type Pool struct {
 	pool      *http_client.Pool
 	opt       Options
 	basicAuth string
+	picker    components.Picker
 }
 
 func NewPool(opt Options) (pool *Pool) {
@@ -33,15 +37,26 @@ func NewPool(opt Options) (pool *Pool) {
 	return
 }
 
+// SetBreaker 使用熔断器保护请求，需要在发起请求之前调用
+func (p *Pool) SetBreaker(breaker *components.Breaker) {
+	p.pool.SetBreaker(breaker)
+}
+
+// SetRetry 使用重试策略，需要在发起请求之前调用
+func (p *Pool) SetRetry(policy *retry.Policy) {
+	p.pool.SetRetry(policy)
+}
+
+// SetPicker 在多个节点间负载均衡，picker中的server为*Node，需要在发起请求之前调用
+func (p *Pool) SetPicker(picker components.Picker) {
+	p.picker = picker
+}
+
 func (p *Pool) Options() Options {
 	return p.opt
 }
 
 func (p *Pool) Request(ctx context.Context, method, path string, body []byte, headers http_client.Headers) (response *http_client.Response, err error) {
-	var (
-		url = fmt.Sprintf("%s/%s", p.opt.BaseUrl, path)
-	)
-
 	if len(headers) == 0 {
 		headers = http_client.Headers{
 			"Content-Type": "application/vnd.elasticsearch+json; compatible-with=7",
@@ -53,7 +68,38 @@ func (p *Pool) Request(ctx context.Context, method, path string, body []byte, he
 		headers["Authorization"] = p.basicAuth
 	}
 
-	return p.pool.Request(ctx, method, url, body, headers)
+	if p.picker == nil {
+		return p.pool.Request(ctx, method, fmt.Sprintf("%s/%s", p.opt.BaseUrl, path), body, headers)
+	}
+
+	// 每次发送(包括重试)都重新选择节点，失败的节点被摘除后重试会换到其他节点
+	return p.pool.RequestPick(ctx, method, func() (string, func(*http_client.Response, error), error) {
+		return p.pick(path)
+	}, body, headers)
+}
+
+func (p *Pool) pick(path string) (url string, report func(*http_client.Response, error), err error) {
+	server, done, err := p.picker.Pick()
+	if err != nil {
+		return "", nil, err
+	}
+
+	node, ok := server.(*Node)
+	if !ok {
+		done.Release()
+		return "", nil, ErrNodeType
+	}
+
+	return fmt.Sprintf("%s/%s", node.Url(), path), func(response *http_client.Response, err error) {
+		switch {
+		case err == nil && response.GetStatus() >= http.StatusInternalServerError:
+			done(ErrNodeStatus)
+		case errors.Is(err, context.Canceled):
+			done.Release()
+		default:
+			done(err)
+		}
+	}, nil
 }
 
 func (p *Pool) SearchBySql(ctx context.Context, size int64, format, sqlStr string, params []any, bodyArgs ...Arg) (res *result.Sql, err error) {
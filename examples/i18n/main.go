@@ -9,25 +9,38 @@ import (
 )
 
 func main() {
+	components.DefaultLang = components.ZhCn
+
 	utils.Green("from yaml:")
-	i18n, err := components.NewI18nFromYaml("zh_CN", "msgs/")
+	i18n, err := components.NewI18nFromYaml("msgs/")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	utils.Fuchsia(i18n.T("param_err", ""))
-	utils.Red(i18n.T("param_err", "en"))
-	utils.Yellow(i18n.T("param_err", "zh_CN"))
+	utils.Fuchsia(i18n.T("param_err"))
+	utils.Red(i18n.Tl("param_err", components.En))
+	utils.Yellow(i18n.Tl("param_err", components.ZhCn))
 
 	fmt.Println()
 
 	utils.Green("from json:")
-	i18n, err = components.NewI18nFromJson("zh_CN", "msgs/")
+	i18n, err = components.NewI18nFromJson("msgs/")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	utils.Fuchsia(i18n.T("param_err", ""))
-	utils.Red(i18n.T("param_err", "en"))
-	utils.Yellow(i18n.T("param_err", "zh_CN"))
+	utils.Fuchsia(i18n.T("param_err"))
+	utils.Red(i18n.Tl("param_err", components.En))
+	utils.Yellow(i18n.Tl("param_err", components.ZhCn))
+
+	fmt.Println()
+
+	utils.Green("interpolation, plurals and fallback:")
+	i18n.SetFallback("zh_HK", components.ZhCn, components.En)
+
+	lang := i18n.Negotiate("en-US,en;q=0.9,zh-CN;q=0.8")
+	utils.Fuchsia(i18n.Tlf("hello", lang, components.Args{"name": "Tom"}))
+	utils.Red(i18n.Tln("unread", lang, 1, nil))
+	utils.Red(i18n.Tln("unread", lang, 3, nil))
+	utils.Yellow(i18n.Tln("unread", "zh_HK", 3, nil))
 }
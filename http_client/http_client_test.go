@@ -1,8 +1,15 @@
 package http_client
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/grpc-boot/base/v3/components"
+	"github.com/grpc-boot/base/v3/httpsign"
+	"github.com/grpc-boot/base/v3/retry"
 )
 
 var (
@@ -18,3 +25,72 @@ func TestClient_GetTimeout(t *testing.T) {
 
 	t.Logf("%d: %s", rp.GetStatus(), rp.GetBody())
 }
+
+func TestPool_SetBreaker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	pool := NewPool(DefaultOptions())
+	pool.SetBreaker(components.NewBreaker("test", components.WithMinRequests(2)))
+
+	for i := 0; i < 2; i++ {
+		rp, err := pool.GetTimeout(time.Second, server.URL, nil)
+		if err != nil || !rp.Is(http.StatusServiceUnavailable) {
+			t.Fatalf("want 503, got %v %v", rp, err)
+		}
+	}
+
+	if _, err := pool.GetTimeout(time.Second, server.URL, nil); err != components.ErrBreakerOpen {
+		t.Fatalf("want ErrBreakerOpen, got %v", err)
+	}
+}
+
+func TestPool_SetRetry(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := io.ReadAll(r.Body)
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	pool := NewPool(DefaultOptions())
+	pool.SetRetry(retry.New(retry.WithMaxAttempts(3), retry.WithBackoff(retry.Constant(time.Millisecond))))
+
+	rp, err := pool.PostTimeout(time.Second, server.URL, []byte("hello"), nil)
+	if err != nil || !rp.Is(http.StatusOK) || string(rp.GetBody()) != "hello" || requests != 3 {
+		t.Fatalf("want 200 hello, got %v %v %d", rp, err, requests)
+	}
+
+	// 重试用尽返回最后一次响应
+	requests = -10
+	rp, err = pool.GetTimeout(time.Second, server.URL, nil)
+	if err != nil || !rp.Is(http.StatusServiceUnavailable) {
+		t.Fatalf("want 503, got %v %v", rp, err)
+	}
+}
+
+func TestPool_SetSigner(t *testing.T) {
+	verifier := httpsign.NewVerifier(httpsign.StaticKeys(map[string]string{"order": "secret"}))
+	server := httptest.NewServer(verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(httpsign.KeyId(r.Context())))
+	})))
+	defer server.Close()
+
+	pool := NewPool(DefaultOptions())
+	if rp, err := pool.PostTimeout(time.Second, server.URL+"/orders?id=1", []byte("hello"), nil); err != nil || !rp.Is(http.StatusUnauthorized) {
+		t.Fatalf("want 401, got %v %v", rp, err)
+	}
+
+	pool.SetSigner(httpsign.NewSigner("order", []byte("secret")))
+	rp, err := pool.PostTimeout(time.Second, server.URL+"/orders?id=1", []byte("hello"), nil)
+	if err != nil || !rp.Is(http.StatusOK) || string(rp.GetBody()) != "order" {
+		t.Fatalf("want 200 order, got %v %v", rp, err)
+	}
+}
@@ -3,20 +3,44 @@ package http_client
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"net"
 	"net/http"
 	"time"
 
+	"github.com/grpc-boot/base/v3/components"
+	"github.com/grpc-boot/base/v3/httpsign"
 	"github.com/grpc-boot/base/v3/logger"
+	"github.com/grpc-boot/base/v3/retry"
 	"github.com/grpc-boot/base/v3/utils"
 
 	"go.uber.org/zap"
 )
 
+var (
+	errServerStatus = errors.New("http server error status")
+)
+
+// retryStatusError 可重试的响应状态码
+type retryStatusError struct {
+	status int
+}
+
+func (rse *retryStatusError) Error() string {
+	return "http retryable status " + http.StatusText(rse.status)
+}
+
+func (rse *retryStatusError) Retryable() bool {
+	return true
+}
+
 type Pool struct {
-	client *http.Client
-	opt    *Options
+	client  *http.Client
+	opt     *Options
+	breaker *components.Breaker
+	retry   *retry.Policy
+	signer  *httpsign.Signer
 }
 
 func NewPool(opt Options) *Pool {
@@ -100,7 +124,68 @@ func (c *Pool) RequestTimeout(timeout time.Duration, method, url string, body []
 	return c.Request(ctx, method, url, body, headers)
 }
 
+// SetRetry 使用重试策略，网络错误和429、502、503、504响应会重试，重试用尽时返回最后一次的响应，
+// 非幂等的请求需要调用方确认可以重复执行，需要在发起请求之前调用
+func (c *Pool) SetRetry(policy *retry.Policy) {
+	c.retry = policy
+}
+
+// SetSigner 使用HMac签名请求，每次发送(包括重试)都会重新生成时间戳和nonce，需要在发起请求之前调用
+func (c *Pool) SetSigner(signer *httpsign.Signer) {
+	c.signer = signer
+}
+
+// UrlPicker 每次发送(包括重试)前选择url，report不为nil时在本次发送结束后调用
+type UrlPicker func() (url string, report func(rp *Response, err error), err error)
+
 func (c *Pool) Request(ctx context.Context, method, url string, body []byte, headers Headers) (rp *Response, err error) {
+	return c.RequestPick(ctx, method, func() (string, func(rp *Response, err error), error) {
+		return url, nil, nil
+	}, body, headers)
+}
+
+// RequestPick 与Request相同，但每次发送都通过pick选择url，重试时可以更换节点
+func (c *Pool) RequestPick(ctx context.Context, method string, pick UrlPicker, body []byte, headers Headers) (rp *Response, err error) {
+	if c.retry == nil {
+		return c.attempt(ctx, method, pick, body, headers)
+	}
+
+	rp, err = retry.DoValue(ctx, c.retry, func(ctx context.Context) (*Response, error) {
+		rp, err := c.attempt(ctx, method, pick, body, headers)
+		if err != nil {
+			return nil, err
+		}
+
+		switch rp.status {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return rp, &retryStatusError{status: rp.status}
+		}
+
+		return rp, nil
+	})
+
+	if _, ok := err.(*retryStatusError); ok {
+		return rp, nil
+	}
+
+	return rp, err
+}
+
+func (c *Pool) attempt(ctx context.Context, method string, pick UrlPicker, body []byte, headers Headers) (rp *Response, err error) {
+	url, report, err := pick()
+	if err != nil {
+		return nil, err
+	}
+
+	rp, err = c.request(ctx, method, url, body, headers)
+	if report != nil {
+		report(rp, err)
+	}
+
+	return
+}
+
+func (c *Pool) request(ctx context.Context, method, url string, body []byte, headers Headers) (rp *Response, err error) {
 	var (
 		start = time.Now()
 		req   *http.Request
@@ -144,6 +229,17 @@ func (c *Pool) Request(ctx context.Context, method, url string, body []byte, hea
 		}
 	}
 
+	if c.signer != nil {
+		if err = c.signer.Sign(req, body); err != nil {
+			logger.Error("sign http request failed",
+				zap.String("Url", url),
+				zap.String("Method", method),
+				zap.NamedError("Error", err),
+			)
+			return
+		}
+	}
+
 	rp, err = c.Do(req)
 	if err != nil {
 		logger.Error("http request failed",
@@ -169,7 +265,35 @@ func (c *Pool) Request(ctx context.Context, method, url string, body []byte, hea
 	return
 }
 
+// SetBreaker 使用熔断器保护请求，网络错误和5xx响应计为失败，调用方取消的请求不计入，需要在发起请求之前调用
+func (c *Pool) SetBreaker(breaker *components.Breaker) {
+	c.breaker = breaker
+}
+
 func (c *Pool) Do(req *http.Request) (rp *Response, err error) {
+	if c.breaker == nil {
+		return c.do(req)
+	}
+
+	done, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	rp, err = c.do(req)
+	switch {
+	case err == nil && rp.status >= http.StatusInternalServerError:
+		done(errServerStatus)
+	case errors.Is(err, context.Canceled):
+		done.Release()
+	default:
+		done(err)
+	}
+
+	return
+}
+
+func (c *Pool) do(req *http.Request) (rp *Response, err error) {
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return
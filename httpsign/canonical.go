@@ -0,0 +1,76 @@
+package httpsign
+
+import (
+	"crypto"
+	_ "crypto/sha256"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/grpc-boot/base/v3/utils"
+)
+
+const (
+	HeaderKeyId     = `X-Sign-Key-Id`
+	HeaderTimestamp = `X-Sign-Timestamp`
+	HeaderNonce     = `X-Sign-Nonce`
+	HeaderSignature = `X-Sign-Signature`
+)
+
+// CanonicalRequest 待签名的规范请求，各部分以换行分隔：
+// METHOD
+// 转义后的path，为空时为/
+// 按key、value排序并转义的query
+// body的sha256(hex)
+// 秒级时间戳
+// nonce
+// keyId
+func CanonicalRequest(method, path, rawQuery string, body []byte, timestamp, nonce, keyId string) (string, error) {
+	query, err := canonicalQuery(rawQuery)
+	if err != nil {
+		return "", err
+	}
+
+	if path == "" {
+		path = "/"
+	}
+
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		query,
+		utils.Hash(body, crypto.SHA256),
+		timestamp,
+		nonce,
+		keyId,
+	}, "\n"), nil
+}
+
+func canonicalQuery(rawQuery string) (string, error) {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", err
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf strings.Builder
+	for _, key := range keys {
+		vals := values[key]
+		sort.Strings(vals)
+		for _, val := range vals {
+			if buf.Len() > 0 {
+				buf.WriteByte('&')
+			}
+			buf.WriteString(url.QueryEscape(key))
+			buf.WriteByte('=')
+			buf.WriteString(url.QueryEscape(val))
+		}
+	}
+
+	return buf.String(), nil
+}
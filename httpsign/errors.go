@@ -0,0 +1,13 @@
+package httpsign
+
+import "errors"
+
+var (
+	ErrSignMissing   = errors.New("httpsign: signature headers are missing")
+	ErrSignMalformed = errors.New("httpsign: signature headers are malformed")
+	ErrSignKey       = errors.New("httpsign: key id not found")
+	ErrSignExpired   = errors.New("httpsign: timestamp out of allowed skew")
+	ErrSignReplay    = errors.New("httpsign: nonce already used")
+	ErrSignMismatch  = errors.New("httpsign: signature is invalid")
+	ErrSignBodySize  = errors.New("httpsign: body is too large")
+)
@@ -0,0 +1,94 @@
+package httpsign
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCanonicalRequest(t *testing.T) {
+	canonical, err := CanonicalRequest("post", "", "b=2&a=3&a=1&c=x+y", []byte("hello"), "1700000000", "n1", "k1")
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	want := "POST\n/\na=1&a=3&b=2&c=x+y\n2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824\n1700000000\nn1\nk1"
+	if canonical != want {
+		t.Fatalf("want %q, got %q", want, canonical)
+	}
+
+	if _, err = CanonicalRequest("GET", "/", "a=%zz", nil, "1", "n", "k"); err == nil {
+		t.Fatal("want query error")
+	}
+}
+
+func TestVerifier_Middleware(t *testing.T) {
+	var (
+		signer   = NewSigner("order", []byte("secret"))
+		verifier = NewVerifier(StaticKeys(map[string]string{"order": "secret"}), WithSkew(time.Minute))
+		handler  = verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			_, _ = w.Write(append([]byte(KeyId(r.Context())+":"), body...))
+		}))
+	)
+
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/orders?id=1&from=app", bytes.NewReader([]byte(body)))
+		if err := signer.Sign(req, []byte(body)); err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+		return req
+	}
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	req := newRequest(`{"amount":1}`)
+	replay := req.Clone(req.Context())
+	replay.Body = io.NopCloser(bytes.NewReader([]byte(`{"amount":1}`)))
+
+	if rec := serve(req); rec.Code != http.StatusOK || rec.Body.String() != `order:{"amount":1}` {
+		t.Fatalf("want 200, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	if err := verifier.Verify(replay); err != ErrSignReplay {
+		t.Fatalf("want ErrSignReplay, got %v", err)
+	}
+
+	// 篡改body
+	tampered := newRequest(`{"amount":1}`)
+	tampered.Body = io.NopCloser(bytes.NewReader([]byte(`{"amount":100}`)))
+	if err := verifier.Verify(tampered); err != ErrSignMismatch {
+		t.Fatalf("want ErrSignMismatch, got %v", err)
+	}
+
+	// 篡改query
+	tampered = newRequest("")
+	tampered.URL.RawQuery = "id=2&from=app"
+	if err := verifier.Verify(tampered); err != ErrSignMismatch {
+		t.Fatalf("want ErrSignMismatch, got %v", err)
+	}
+
+	signer.now = func() time.Time { return time.Now().Add(-time.Minute * 2) }
+	if err := verifier.Verify(newRequest("")); err != ErrSignExpired {
+		t.Fatalf("want ErrSignExpired, got %v", err)
+	}
+	signer.now = time.Now
+
+	unknown := NewSigner("other", []byte("secret"))
+	req = httptest.NewRequest(http.MethodGet, "/orders", nil)
+	_ = unknown.Sign(req, nil)
+	if err := verifier.Verify(req); err != ErrSignKey {
+		t.Fatalf("want ErrSignKey, got %v", err)
+	}
+
+	if rec := serve(httptest.NewRequest(http.MethodGet, "/orders", nil)); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("want 401, got %d", rec.Code)
+	}
+}
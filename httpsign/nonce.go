@@ -0,0 +1,47 @@
+package httpsign
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	nonceStoreSweep = 1024
+)
+
+// MemoryNonceStore 内存nonce存储，多实例部署时应使用共享存储
+type MemoryNonceStore struct {
+	mutex sync.Mutex
+	used  map[string]time.Time
+	now   func() time.Time
+}
+
+// NewMemoryNonceStore 实例化内存nonce存储
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		used: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+func (ms *MemoryNonceStore) Use(key string, ttl time.Duration) bool {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	now := ms.now()
+	if expireAt, exists := ms.used[key]; exists && expireAt.After(now) {
+		return false
+	}
+
+	// 记录较多时顺带清理过期记录
+	if len(ms.used) >= nonceStoreSweep {
+		for k, expireAt := range ms.used {
+			if !expireAt.After(now) {
+				delete(ms.used, k)
+			}
+		}
+	}
+
+	ms.used[key] = now.Add(ttl)
+	return true
+}
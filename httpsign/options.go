@@ -0,0 +1,67 @@
+package httpsign
+
+import (
+	"crypto"
+	"time"
+)
+
+var (
+	defaultOptions = func() *Options {
+		return &Options{
+			hash:    crypto.SHA256,
+			skew:    time.Minute * 5,
+			maxBody: 10 << 20,
+		}
+	}
+)
+
+// NonceStore 记录已使用的nonce，默认为MemoryNonceStore，多实例部署时应使用共享存储
+type NonceStore interface {
+	// Use 标记key已使用并保留ttl时间，已使用过时返回false
+	Use(key string, ttl time.Duration) (ok bool)
+}
+
+type Options struct {
+	hash       crypto.Hash
+	skew       time.Duration
+	nonceStore NonceStore
+	maxBody    int64
+}
+
+type Option func(opts *Options)
+
+func loadOptions(options ...Option) *Options {
+	opts := defaultOptions()
+	for _, option := range options {
+		option(opts)
+	}
+	return opts
+}
+
+// WithHash HMac使用的哈希算法，签名和验签需一致，默认SHA256
+func WithHash(hash crypto.Hash) Option {
+	return func(opts *Options) {
+		opts.hash = hash
+	}
+}
+
+// WithSkew 验签时允许的时间偏差，默认5分钟
+func WithSkew(skew time.Duration) Option {
+	return func(opts *Options) {
+		opts.skew = skew
+	}
+}
+
+// WithNonceStore 防重放存储，默认为MemoryNonceStore
+func WithNonceStore(store NonceStore) Option {
+	return func(opts *Options) {
+		opts.nonceStore = store
+	}
+}
+
+// WithMaxBody 验签时读取body的最大字节数，默认10MB
+func WithMaxBody(size int64) Option {
+	return func(opts *Options) {
+		opts.maxBody = size
+	}
+}
@@ -0,0 +1,52 @@
+package httpsign
+
+import (
+	"crypto/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grpc-boot/base/v3/utils"
+)
+
+// Signer 请求签名，可以在多个goroutine中复用
+type Signer struct {
+	keyId  string
+	secret []byte
+	opts   *Options
+	now    func() time.Time
+}
+
+// NewSigner 实例化请求签名
+func NewSigner(keyId string, secret []byte, opts ...Option) *Signer {
+	return &Signer{
+		keyId:  keyId,
+		secret: secret,
+		opts:   loadOptions(opts...),
+		now:    time.Now,
+	}
+}
+
+// Sign 计算签名并设置到请求头，body需与请求实际发送的内容一致
+func (s *Signer) Sign(req *http.Request, body []byte) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	var (
+		timestamp = strconv.FormatInt(s.now().Unix(), 10)
+		nonceHex  = utils.HexEncode2String(nonce)
+	)
+
+	canonical, err := CanonicalRequest(req.Method, req.URL.EscapedPath(), req.URL.RawQuery, body, timestamp, nonceHex, s.keyId)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(HeaderKeyId, s.keyId)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonceHex)
+	req.Header.Set(HeaderSignature, utils.HMac(s.secret, utils.String2Bytes(canonical), s.opts.hash))
+	return nil
+}
@@ -0,0 +1,144 @@
+package httpsign
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grpc-boot/base/v3/logger"
+	"github.com/grpc-boot/base/v3/utils"
+
+	"go.uber.org/zap"
+)
+
+type keyIdCtx struct{}
+
+// KeyFunc 根据keyId查找密钥，不存在时返回false
+type KeyFunc func(keyId string) (secret []byte, ok bool)
+
+// StaticKeys 固定的keyId与密钥
+func StaticKeys(keys map[string]string) KeyFunc {
+	return func(keyId string) ([]byte, bool) {
+		secret, ok := keys[keyId]
+		return []byte(secret), ok
+	}
+}
+
+// KeyId 验签通过的请求中调用方的keyId
+func KeyId(ctx context.Context) string {
+	keyId, _ := ctx.Value(keyIdCtx{}).(string)
+	return keyId
+}
+
+// Verifier 请求验签，可以在多个goroutine中复用
+type Verifier struct {
+	keys KeyFunc
+	opts *Options
+	now  func() time.Time
+}
+
+// NewVerifier 实例化请求验签
+func NewVerifier(keys KeyFunc, opts ...Option) *Verifier {
+	v := &Verifier{
+		keys: keys,
+		opts: loadOptions(opts...),
+		now:  time.Now,
+	}
+
+	if v.opts.nonceStore == nil {
+		v.opts.nonceStore = NewMemoryNonceStore()
+	}
+
+	return v
+}
+
+// Verify 校验签名、时间偏差和nonce，会读取body并重新设置r.Body供后续读取
+func (v *Verifier) Verify(r *http.Request) error {
+	var (
+		keyId     = r.Header.Get(HeaderKeyId)
+		timestamp = r.Header.Get(HeaderTimestamp)
+		nonce     = r.Header.Get(HeaderNonce)
+		signature = r.Header.Get(HeaderSignature)
+	)
+
+	if keyId == "" || timestamp == "" || nonce == "" || signature == "" {
+		return ErrSignMissing
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrSignMalformed
+	}
+
+	if diff := v.now().Sub(time.Unix(unix, 0)); diff > v.opts.skew || diff < -v.opts.skew {
+		return ErrSignExpired
+	}
+
+	secret, ok := v.keys(keyId)
+	if !ok {
+		return ErrSignKey
+	}
+
+	body, err := v.readBody(r)
+	if err != nil {
+		return err
+	}
+
+	canonical, err := CanonicalRequest(r.Method, r.URL.EscapedPath(), r.URL.RawQuery, body, timestamp, nonce, keyId)
+	if err != nil {
+		return ErrSignMalformed
+	}
+
+	expected := utils.HMacBytes(secret, utils.String2Bytes(canonical), v.opts.hash)
+	if subtle.ConstantTimeCompare(expected, utils.String2Bytes(signature)) != 1 {
+		return ErrSignMismatch
+	}
+
+	// 签名通过后再记录nonce，防止伪造请求占用nonce；超出偏差的请求已被拒绝，保留2倍偏差即可
+	if !v.opts.nonceStore.Use(keyId+":"+nonce, v.opts.skew*2) {
+		return ErrSignReplay
+	}
+
+	return nil
+}
+
+// Middleware 验签中间件，失败时响应401，通过时可以用KeyId(r.Context())获取调用方
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.Verify(r); err != nil {
+			logger.Warn("http sign verify failed",
+				zap.String("Method", r.Method),
+				zap.String("Path", r.URL.Path),
+				zap.String("KeyId", r.Header.Get(HeaderKeyId)),
+				zap.NamedError("Error", err),
+			)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), keyIdCtx{}, r.Header.Get(HeaderKeyId))))
+	})
+}
+
+func (v *Verifier) readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, v.opts.maxBody+1))
+	_ = r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > v.opts.maxBody {
+		return nil, ErrSignBodySize
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,167 @@
+package jwt
+
+import (
+	"time"
+
+	"github.com/grpc-boot/base/v3/kind"
+	"github.com/grpc-boot/base/v3/utils"
+)
+
+// Claims 标准声明，私有声明存放在Data中
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt int64
+	NotBefore int64
+	IssuedAt  int64
+	Id        string
+	Data      kind.JsonParam
+}
+
+// NewClaims 实例化Claims，iat为当前时间，ttl>0时设置exp
+func NewClaims(subject string, ttl time.Duration) *Claims {
+	now := time.Now()
+	c := &Claims{
+		Subject:  subject,
+		IssuedAt: now.Unix(),
+	}
+
+	if ttl > 0 {
+		c.ExpiresAt = now.Add(ttl).Unix()
+	}
+
+	return c
+}
+
+// Set 设置私有声明
+func (c *Claims) Set(key string, value any) *Claims {
+	if c.Data == nil {
+		c.Data = kind.JsonParam{}
+	}
+
+	c.Data[key] = value
+	return c
+}
+
+// HasAudience 是否包含audience
+func (c *Claims) HasAudience(audience string) bool {
+	for _, aud := range c.Audience {
+		if aud == audience {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c Claims) MarshalJSON() ([]byte, error) {
+	data := make(map[string]any, len(c.Data)+7)
+	for key, value := range c.Data {
+		data[key] = value
+	}
+
+	if c.Issuer != "" {
+		data["iss"] = c.Issuer
+	}
+
+	if c.Subject != "" {
+		data["sub"] = c.Subject
+	}
+
+	switch len(c.Audience) {
+	case 0:
+	case 1:
+		data["aud"] = c.Audience[0]
+	default:
+		data["aud"] = c.Audience
+	}
+
+	if c.ExpiresAt > 0 {
+		data["exp"] = c.ExpiresAt
+	}
+
+	if c.NotBefore > 0 {
+		data["nbf"] = c.NotBefore
+	}
+
+	if c.IssuedAt > 0 {
+		data["iat"] = c.IssuedAt
+	}
+
+	if c.Id != "" {
+		data["jti"] = c.Id
+	}
+
+	return utils.JsonMarshal(data)
+}
+
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	var param kind.JsonParam
+	if err := utils.JsonUnmarshal(data, &param); err != nil {
+		return err
+	}
+
+	c.Issuer = param.String("iss")
+	c.Subject = param.String("sub")
+	c.ExpiresAt = param.Int64("exp")
+	c.NotBefore = param.Int64("nbf")
+	c.IssuedAt = param.Int64("iat")
+	c.Id = param.String("jti")
+
+	if aud, ok := param["aud"].(string); ok {
+		c.Audience = []string{aud}
+	} else {
+		c.Audience = param.StringSlice("aud")
+	}
+
+	for _, key := range []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti"} {
+		delete(param, key)
+	}
+
+	c.Data = param
+	return nil
+}
+
+func (c *Claims) validate(opts *Options) error {
+	var (
+		now    = opts.now().Unix()
+		leeway = int64(opts.leeway / time.Second)
+	)
+
+	if c.ExpiresAt == 0 && opts.requireExp {
+		return ErrTokenRequiredExp
+	}
+
+	if c.ExpiresAt > 0 && now > c.ExpiresAt+leeway {
+		return ErrTokenExpired
+	}
+
+	if c.NotBefore > 0 && now+leeway < c.NotBefore {
+		return ErrTokenNotValidYet
+	}
+
+	if c.IssuedAt > 0 && now+leeway < c.IssuedAt {
+		return ErrTokenUsedBeforeIat
+	}
+
+	if opts.issuer != "" && c.Issuer != opts.issuer {
+		return ErrTokenIssuer
+	}
+
+	if len(opts.audience) > 0 {
+		matched := false
+		for _, aud := range opts.audience {
+			if c.HasAudience(aud) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return ErrTokenAudience
+		}
+	}
+
+	return nil
+}
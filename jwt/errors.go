@@ -0,0 +1,19 @@
+package jwt
+
+import "errors"
+
+var (
+	ErrTokenMalformed     = errors.New("jwt: token is malformed")
+	ErrTokenSignature     = errors.New("jwt: signature is invalid")
+	ErrTokenExpired       = errors.New("jwt: token is expired")
+	ErrTokenNotValidYet   = errors.New("jwt: token is not valid yet")
+	ErrTokenUsedBeforeIat = errors.New("jwt: token used before issued")
+	ErrTokenAudience      = errors.New("jwt: token has invalid audience")
+	ErrTokenIssuer        = errors.New("jwt: token has invalid issuer")
+	ErrTokenRequiredExp   = errors.New("jwt: token is missing exp")
+	ErrAlgorithm          = errors.New("jwt: algorithm is not supported")
+	ErrAlgorithmNotMatch  = errors.New("jwt: algorithm does not match key")
+	ErrKeyNotFound        = errors.New("jwt: key not found")
+	ErrKeyInvalid         = errors.New("jwt: key is invalid")
+	ErrKeyringEmpty       = errors.New("jwt: keyring is empty")
+)
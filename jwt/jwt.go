@@ -0,0 +1,156 @@
+package jwt
+
+import (
+	"bytes"
+	"slices"
+
+	"github.com/grpc-boot/base/v3/utils"
+)
+
+const (
+	typJwt = `JWT`
+)
+
+// Header JOSE头部
+type Header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// Jwt 签发和校验token
+type Jwt struct {
+	keyring *Keyring
+	opts    *Options
+}
+
+// New 实例化Jwt
+func New(keyring *Keyring, opts ...Option) *Jwt {
+	return &Jwt{
+		keyring: keyring,
+		opts:    loadOptions(opts...),
+	}
+}
+
+// Keyring 密钥环
+func (j *Jwt) Keyring() *Keyring {
+	return j.keyring
+}
+
+// Issue 使用主密钥签发token
+func (j *Jwt) Issue(claims *Claims) (token string, err error) {
+	key, err := j.keyring.Primary()
+	if err != nil {
+		return "", err
+	}
+
+	// 在副本上设置默认签发者，不修改调用方的claims
+	if claims.Issuer == "" && j.opts.issuer != "" {
+		withIssuer := *claims
+		withIssuer.Issuer = j.opts.issuer
+		claims = &withIssuer
+	}
+
+	header, err := utils.JsonMarshal(Header{
+		Alg: key.alg,
+		Typ: typJwt,
+		Kid: key.kid,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := utils.JsonMarshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(header)*4/3 + len(payload)*4/3 + 96)
+	buf.Write(utils.Base64RawUrlEncode(header))
+	buf.WriteByte('.')
+	buf.Write(utils.Base64RawUrlEncode(payload))
+
+	signature, err := key.sign(buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	buf.WriteByte('.')
+	buf.Write(utils.Base64RawUrlEncode(signature))
+	return buf.String(), nil
+}
+
+// Verify 校验签名和声明，返回Claims
+func (j *Jwt) Verify(token string) (claims *Claims, err error) {
+	_, claims, err = j.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = claims.validate(j.opts); err != nil {
+		return claims, err
+	}
+
+	return claims, nil
+}
+
+// Parse 校验签名并解析token，不校验声明
+func (j *Jwt) Parse(token string) (header *Header, claims *Claims, err error) {
+	data := utils.String2Bytes(token)
+
+	first := bytes.IndexByte(data, '.')
+	last := bytes.LastIndexByte(data, '.')
+	if first < 1 || last == first {
+		return nil, nil, ErrTokenMalformed
+	}
+
+	headerData, err := utils.Base64RawUrlDecode(data[:first])
+	if err != nil {
+		return nil, nil, ErrTokenMalformed
+	}
+
+	header = &Header{}
+	if err = utils.JsonUnmarshal(headerData, header); err != nil {
+		return nil, nil, ErrTokenMalformed
+	}
+
+	if _, exists := algHash[header.Alg]; !exists && header.Alg != EdDSA {
+		return nil, nil, ErrAlgorithm
+	}
+
+	if len(j.opts.algorithms) > 0 && !slices.Contains(j.opts.algorithms, header.Alg) {
+		return nil, nil, ErrAlgorithm
+	}
+
+	key, err := j.keyring.lookup(header.Kid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 防止算法混淆，token声明的算法必须与密钥算法一致
+	if key.alg != header.Alg {
+		return nil, nil, ErrAlgorithmNotMatch
+	}
+
+	signature, err := utils.Base64RawUrlDecode(data[last+1:])
+	if err != nil {
+		return nil, nil, ErrTokenMalformed
+	}
+
+	if !key.verify(data[:last], signature) {
+		return nil, nil, ErrTokenSignature
+	}
+
+	payload, err := utils.Base64RawUrlDecode(data[first+1 : last])
+	if err != nil {
+		return nil, nil, ErrTokenMalformed
+	}
+
+	claims = &Claims{}
+	if err = utils.JsonUnmarshal(payload, claims); err != nil {
+		return nil, nil, ErrTokenMalformed
+	}
+
+	return header, claims, nil
+}
@@ -0,0 +1,159 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-boot/base/v3/components"
+)
+
+func TestJwt_Verify(t *testing.T) {
+	privateKey, publicKey := components.CreatePkcs8Keys(2048)
+	r, _ := components.NewRsa(publicKey, privateKey)
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	ecPrivate, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	var keys []*Key
+	for _, alg := range []string{HS256, HS384, HS512} {
+		key, err := NewHmacKey("h-"+alg, alg, []byte("b#%*N130js&@1nuc"))
+		if err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+		keys = append(keys, key)
+	}
+
+	for _, alg := range []string{RS256, RS384, RS512, PS256} {
+		key, err := NewRsaKey("r-"+alg, alg, r)
+		if err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+		keys = append(keys, key)
+	}
+
+	edKey, _ := NewEd25519Key("ed", edPublic, edPrivate)
+	ecKey, _ := NewEcdsaKey("ec", nil, ecPrivate)
+	keys = append(keys, edKey, ecKey)
+
+	keyring := NewKeyring(keys...)
+	j := New(keyring, WithIssuer("base"), WithAudience("api"))
+
+	for _, key := range keys {
+		if err := keyring.UsePrimary(key.Kid()); err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+
+		claims := NewClaims("user:1", time.Minute)
+		claims.Audience = []string{"api", "admin"}
+		claims.Set("role", "admin")
+
+		token, err := j.Issue(claims)
+		if err != nil {
+			t.Fatalf("%s want nil, got %v", key.Alg(), err)
+		}
+
+		parsed, err := j.Verify(token)
+		if err != nil {
+			t.Fatalf("%s want nil, got %v", key.Alg(), err)
+		}
+
+		if parsed.Subject != "user:1" || parsed.Issuer != "base" || parsed.Data.String("role") != "admin" {
+			t.Fatalf("%s want user:1/base/admin, got %s/%s/%s", key.Alg(), parsed.Subject, parsed.Issuer, parsed.Data.String("role"))
+		}
+
+		// 默认签发者不写回调用方的claims
+		if claims.Issuer != "" {
+			t.Fatalf("%s want empty issuer, got %s", key.Alg(), claims.Issuer)
+		}
+
+		tampered := token[:len(token)-2] + "AA"
+		if strings.HasSuffix(token, "AA") {
+			tampered = token[:len(token)-2] + "BB"
+		}
+
+		if _, err = j.Verify(tampered); err != ErrTokenSignature && err != ErrTokenMalformed {
+			t.Fatalf("%s want %v, got %v", key.Alg(), ErrTokenSignature, err)
+		}
+	}
+}
+
+func TestJwt_VerifyClaims(t *testing.T) {
+	key, _ := NewHmacKey("k1", HS256, []byte("secret"))
+	var (
+		now = time.Unix(1700000000, 0)
+		j   = New(NewKeyring(key), WithNow(func() time.Time { return now }), WithLeeway(time.Second*5), WithIssuer("base"), WithAudience("api"))
+	)
+
+	cases := []struct {
+		claims Claims
+		want   error
+	}{
+		{Claims{Issuer: "base", Audience: []string{"api"}, ExpiresAt: now.Unix() - 3}, nil},
+		{Claims{Issuer: "base", Audience: []string{"api"}, ExpiresAt: now.Unix() - 10}, ErrTokenExpired},
+		{Claims{Issuer: "base", Audience: []string{"api"}, NotBefore: now.Unix() + 10}, ErrTokenNotValidYet},
+		{Claims{Issuer: "base", Audience: []string{"api"}, IssuedAt: now.Unix() + 10}, ErrTokenUsedBeforeIat},
+		{Claims{Issuer: "other", Audience: []string{"api"}}, ErrTokenIssuer},
+		{Claims{Issuer: "base", Audience: []string{"web"}}, ErrTokenAudience},
+	}
+
+	for index, c := range cases {
+		token, err := j.Issue(&c.claims)
+		if err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+
+		if _, err = j.Verify(token); err != c.want {
+			t.Fatalf("case %d want %v, got %v", index, c.want, err)
+		}
+	}
+
+	strict := New(NewKeyring(key), WithRequireExp())
+	token, _ := strict.Issue(&Claims{Subject: "user:1"})
+	if _, err := strict.Verify(token); err != ErrTokenRequiredExp {
+		t.Fatalf("want %v, got %v", ErrTokenRequiredExp, err)
+	}
+}
+
+func TestJwt_VerifyKeyring(t *testing.T) {
+	hsKey, _ := NewHmacKey("hs", HS256, []byte("secret"))
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	edKey, _ := NewEd25519Key("ed", edPublic, edPrivate)
+	verifyOnly, _ := NewEd25519Key("ed", edPublic, nil)
+
+	issuer := New(NewKeyring(edKey))
+	token, err := issuer.Issue(NewClaims("user:1", time.Minute))
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if _, err = New(NewKeyring(verifyOnly)).Verify(token); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if _, err = New(NewKeyring(hsKey)).Verify(token); err != ErrKeyNotFound {
+		t.Fatalf("want %v, got %v", ErrKeyNotFound, err)
+	}
+
+	if _, err = New(NewKeyring(edKey), WithAlgorithms(HS256)).Verify(token); err != ErrAlgorithm {
+		t.Fatalf("want %v, got %v", ErrAlgorithm, err)
+	}
+
+	if _, err = New(NewKeyring(verifyOnly)).Issue(NewClaims("user:1", 0)); err != ErrKeyringEmpty {
+		t.Fatalf("want %v, got %v", ErrKeyringEmpty, err)
+	}
+
+	// 主密钥被替换为仅可校验的密钥后不能再签发
+	rotated := NewKeyring(edKey)
+	rotated.Add(verifyOnly)
+	if _, err = New(rotated).Issue(NewClaims("user:1", 0)); err != ErrKeyringEmpty {
+		t.Fatalf("want %v, got %v", ErrKeyringEmpty, err)
+	}
+
+	if _, err = issuer.Verify("a.b"); err != ErrTokenMalformed {
+		t.Fatalf("want %v, got %v", ErrTokenMalformed, err)
+	}
+}
@@ -0,0 +1,325 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	"github.com/grpc-boot/base/v3/components"
+	"github.com/grpc-boot/base/v3/utils"
+)
+
+const (
+	HS256 = `HS256`
+	HS384 = `HS384`
+	HS512 = `HS512`
+	RS256 = `RS256`
+	RS384 = `RS384`
+	RS512 = `RS512`
+	PS256 = `PS256`
+	ES256 = `ES256`
+	EdDSA = `EdDSA`
+)
+
+var (
+	algHash = map[string]crypto.Hash{
+		HS256: crypto.SHA256,
+		HS384: crypto.SHA384,
+		HS512: crypto.SHA512,
+		RS256: crypto.SHA256,
+		RS384: crypto.SHA384,
+		RS512: crypto.SHA512,
+		PS256: crypto.SHA256,
+		ES256: crypto.SHA256,
+	}
+)
+
+// Key 签名密钥，只有验签密钥时只能校验token
+type Key struct {
+	kid    string
+	alg    string
+	sign   func(data []byte) ([]byte, error)
+	verify func(data, sign []byte) bool
+}
+
+// Kid 密钥id
+func (k *Key) Kid() string {
+	return k.kid
+}
+
+// Alg 签名算法
+func (k *Key) Alg() string {
+	return k.alg
+}
+
+// CanSign 是否可以签发token
+func (k *Key) CanSign() bool {
+	return k.sign != nil
+}
+
+// NewHmacKey HS256/HS384/HS512密钥
+func NewHmacKey(kid, alg string, secret []byte) (*Key, error) {
+	if alg != HS256 && alg != HS384 && alg != HS512 {
+		return nil, ErrAlgorithm
+	}
+
+	if len(secret) < 1 {
+		return nil, ErrKeyInvalid
+	}
+
+	hash := algHash[alg]
+	sign := func(data []byte) ([]byte, error) {
+		return utils.HexDecode(utils.HMacBytes(secret, data, hash))
+	}
+
+	return &Key{
+		kid:  kid,
+		alg:  alg,
+		sign: sign,
+		verify: func(data, signature []byte) bool {
+			expected, err := sign(data)
+			return err == nil && hmac.Equal(expected, signature)
+		},
+	}, nil
+}
+
+// NewRsaKey RS256/RS384/RS512/PS256密钥，kid为空时使用公钥指纹
+func NewRsaKey(kid, alg string, r *components.Rsa) (*Key, error) {
+	hash, exists := algHash[alg]
+	if !exists || (alg != RS256 && alg != RS384 && alg != RS512 && alg != PS256) {
+		return nil, ErrAlgorithm
+	}
+
+	if r == nil || r.PublicKey() == nil {
+		return nil, ErrKeyInvalid
+	}
+
+	if kid == "" {
+		kid = r.Kid()
+	}
+
+	key := &Key{
+		kid: kid,
+		alg: alg,
+	}
+
+	if alg == PS256 {
+		key.verify = func(data, signature []byte) bool {
+			return r.VerifyPSS(data, signature, hash)
+		}
+
+		if r.PrivateKey() != nil {
+			key.sign = func(data []byte) ([]byte, error) {
+				return r.SignPSS(data, hash)
+			}
+		}
+
+		return key, nil
+	}
+
+	key.verify = func(data, signature []byte) bool {
+		return r.Verify(data, signature, hash)
+	}
+
+	if r.PrivateKey() != nil {
+		key.sign = func(data []byte) ([]byte, error) {
+			return r.Sign(data, hash)
+		}
+	}
+
+	return key, nil
+}
+
+// NewEd25519Key EdDSA密钥，privateKey可以为空
+func NewEd25519Key(kid string, publicKey ed25519.PublicKey, privateKey ed25519.PrivateKey) (*Key, error) {
+	if publicKey == nil && privateKey != nil {
+		publicKey = privateKey.Public().(ed25519.PublicKey)
+	}
+
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, ErrKeyInvalid
+	}
+
+	key := &Key{
+		kid: kid,
+		alg: EdDSA,
+		verify: func(data, signature []byte) bool {
+			return ed25519.Verify(publicKey, data, signature)
+		},
+	}
+
+	if len(privateKey) == ed25519.PrivateKeySize {
+		key.sign = func(data []byte) ([]byte, error) {
+			return ed25519.Sign(privateKey, data), nil
+		}
+	}
+
+	return key, nil
+}
+
+// NewEcdsaKey ES256密钥，曲线必须为P-256，privateKey可以为空
+func NewEcdsaKey(kid string, publicKey *ecdsa.PublicKey, privateKey *ecdsa.PrivateKey) (*Key, error) {
+	if publicKey == nil && privateKey != nil {
+		publicKey = &privateKey.PublicKey
+	}
+
+	if publicKey == nil || publicKey.Curve != elliptic.P256() {
+		return nil, ErrKeyInvalid
+	}
+
+	const size = 32
+
+	key := &Key{
+		kid: kid,
+		alg: ES256,
+		verify: func(data, signature []byte) bool {
+			if len(signature) != 2*size {
+				return false
+			}
+
+			digest := hashSum(crypto.SHA256, data)
+			r := new(big.Int).SetBytes(signature[:size])
+			s := new(big.Int).SetBytes(signature[size:])
+			return ecdsa.Verify(publicKey, digest, r, s)
+		},
+	}
+
+	if privateKey != nil {
+		key.sign = func(data []byte) ([]byte, error) {
+			r, s, err := ecdsa.Sign(rand.Reader, privateKey, hashSum(crypto.SHA256, data))
+			if err != nil {
+				return nil, err
+			}
+
+			// JWS使用定长的R||S，而不是ASN.1
+			signature := make([]byte, 2*size)
+			r.FillBytes(signature[:size])
+			s.FillBytes(signature[size:])
+			return signature, nil
+		}
+	}
+
+	return key, nil
+}
+
+func hashSum(hash crypto.Hash, data []byte) []byte {
+	h := hash.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// Keyring 密钥环，使用主密钥签发，根据kid选择密钥校验
+type Keyring struct {
+	mutex   sync.RWMutex
+	primary string
+	keys    map[string]*Key
+}
+
+// NewKeyring 实例化密钥环，第一个可签名的密钥为主密钥
+func NewKeyring(keys ...*Key) *Keyring {
+	kr := &Keyring{
+		keys: make(map[string]*Key, len(keys)),
+	}
+
+	for _, key := range keys {
+		kr.Add(key)
+	}
+
+	return kr
+}
+
+// Add 添加密钥，已存在的kid会被替换，主密钥被替换为仅可校验的密钥时清空主密钥
+func (kr *Keyring) Add(key *Key) {
+	kr.mutex.Lock()
+	defer kr.mutex.Unlock()
+
+	kr.keys[key.kid] = key
+	switch {
+	case kr.primary == "" && key.CanSign():
+		kr.primary = key.kid
+	case kr.primary == key.kid && !key.CanSign():
+		kr.primary = ""
+	}
+}
+
+// Remove 移除密钥
+func (kr *Keyring) Remove(kid string) {
+	kr.mutex.Lock()
+	defer kr.mutex.Unlock()
+
+	delete(kr.keys, kid)
+	if kr.primary == kid {
+		kr.primary = ""
+	}
+}
+
+// UsePrimary 设置签发使用的主密钥
+func (kr *Keyring) UsePrimary(kid string) error {
+	kr.mutex.Lock()
+	defer kr.mutex.Unlock()
+
+	key, exists := kr.keys[kid]
+	if !exists {
+		return ErrKeyNotFound
+	}
+
+	if !key.CanSign() {
+		return ErrKeyInvalid
+	}
+
+	kr.primary = kid
+	return nil
+}
+
+// Key 根据kid获取密钥
+func (kr *Keyring) Key(kid string) (key *Key, exists bool) {
+	kr.mutex.RLock()
+	defer kr.mutex.RUnlock()
+
+	key, exists = kr.keys[kid]
+	return
+}
+
+// Primary 主密钥
+func (kr *Keyring) Primary() (key *Key, err error) {
+	kr.mutex.RLock()
+	defer kr.mutex.RUnlock()
+
+	if kr.primary == "" {
+		return nil, ErrKeyringEmpty
+	}
+
+	key = kr.keys[kr.primary]
+	if key == nil || !key.CanSign() {
+		return nil, ErrKeyInvalid
+	}
+
+	return key, nil
+}
+
+// lookup token没有kid时，仅当密钥环只有一个密钥时使用该密钥
+func (kr *Keyring) lookup(kid string) (*Key, error) {
+	kr.mutex.RLock()
+	defer kr.mutex.RUnlock()
+
+	if kid != "" {
+		key, exists := kr.keys[kid]
+		if !exists {
+			return nil, ErrKeyNotFound
+		}
+		return key, nil
+	}
+
+	if len(kr.keys) == 1 {
+		for _, key := range kr.keys {
+			return key, nil
+		}
+	}
+
+	return nil, ErrKeyNotFound
+}
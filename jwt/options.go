@@ -0,0 +1,72 @@
+package jwt
+
+import "time"
+
+var (
+	defaultOptions = func() *Options {
+		return &Options{
+			now: time.Now,
+		}
+	}
+)
+
+type Options struct {
+	leeway     time.Duration
+	issuer     string
+	audience   []string
+	algorithms []string
+	requireExp bool
+	now        func() time.Time
+}
+
+type Option func(opts *Options)
+
+func loadOptions(options ...Option) *Options {
+	opts := defaultOptions()
+	for _, option := range options {
+		option(opts)
+	}
+	return opts
+}
+
+// WithLeeway 校验exp、nbf、iat时允许的时钟偏差
+func WithLeeway(leeway time.Duration) Option {
+	return func(opts *Options) {
+		opts.leeway = leeway
+	}
+}
+
+// WithIssuer 校验iss必须等于issuer，签发时未设置iss则使用issuer
+func WithIssuer(issuer string) Option {
+	return func(opts *Options) {
+		opts.issuer = issuer
+	}
+}
+
+// WithAudience 校验aud必须包含audience中的任意一个
+func WithAudience(audience ...string) Option {
+	return func(opts *Options) {
+		opts.audience = audience
+	}
+}
+
+// WithAlgorithms 只接受指定算法的token
+func WithAlgorithms(algorithms ...string) Option {
+	return func(opts *Options) {
+		opts.algorithms = algorithms
+	}
+}
+
+// WithRequireExp token必须包含exp
+func WithRequireExp() Option {
+	return func(opts *Options) {
+		opts.requireExp = true
+	}
+}
+
+// WithNow 设置当前时间函数，用于测试
+func WithNow(now func() time.Time) Option {
+	return func(opts *Options) {
+		opts.now = now
+	}
+}
@@ -95,6 +95,28 @@ func TestNewShardMap(t *testing.T) {
 	t.Logf("shard length:%v", sm.ShardLength())
 }
 
+func TestShardMapGetOrSet(t *testing.T) {
+	sm := NewAtomicShardMap[string]()
+
+	actual, loaded := sm.GetOrSet("a", 1)
+	if loaded || actual != 1 {
+		t.Fatalf("want 1 false, got %v %v", actual, loaded)
+	}
+
+	actual, loaded = sm.GetOrSet("a", 2)
+	if !loaded || actual != 1 {
+		t.Fatalf("want 1 true, got %v %v", actual, loaded)
+	}
+
+	if sm.DeleteIf("a", func(value any) bool { return value == 2 }) {
+		t.Fatalf("want false, got true")
+	}
+
+	if !sm.DeleteIf("a", func(value any) bool { return value == 1 }) || sm.Length() != 0 {
+		t.Fatalf("want deleted, got %d", sm.Length())
+	}
+}
+
 func TestNewConcurrentSet(t *testing.T) {
 	d := -5
 	c := uint32(d)
@@ -11,7 +11,19 @@ type Shard[K Key] interface {
 	Range(handler func(key K, value any) bool) bool
 }
 
+// AtomicShard 支持在锁内读取或设置、条件删除的Shard
+type AtomicShard[K Key] interface {
+	Shard[K]
+	GetOrSet(key K, value any) (actual any, loaded bool)
+	DeleteIf(key K, condition func(value any) bool) (deleted bool)
+}
+
 func NewShard[K Key](initSize int) Shard[K] {
+	return NewAtomicShard[K](initSize)
+}
+
+// NewAtomicShard 实例化AtomicShard
+func NewAtomicShard[K Key](initSize int) AtomicShard[K] {
 	return &shard[K]{
 		items: make(map[K]any, initSize),
 	}
@@ -39,6 +51,18 @@ func (s *shard[K]) Get(key K) (value any, exists bool) {
 	return
 }
 
+func (s *shard[K]) GetOrSet(key K, value any) (actual any, loaded bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if actual, loaded = s.items[key]; loaded {
+		return
+	}
+
+	s.items[key] = value
+	return value, false
+}
+
 func (s *shard[K]) Exists(key K) (exists bool) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
@@ -62,6 +86,19 @@ func (s *shard[K]) Delete(keys ...K) (delNum int) {
 	return
 }
 
+func (s *shard[K]) DeleteIf(key K, condition func(value any) bool) (deleted bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	value, exists := s.items[key]
+	if !exists || !condition(value) {
+		return false
+	}
+
+	delete(s.items, key)
+	return true
+}
+
 func (s *shard[K]) Length() int64 {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
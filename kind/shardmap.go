@@ -25,18 +25,32 @@ type ShardMap[K Key] interface {
 	Range(handler func(key K, value any) bool)
 }
 
+// AtomicShardMap 支持在分片锁内读取或设置、条件删除的ShardMap
+type AtomicShardMap[K Key] interface {
+	ShardMap[K]
+	// GetOrSet key存在时返回已存在的值，否则存储value
+	GetOrSet(key K, value any) (actual any, loaded bool)
+	// DeleteIf 在分片锁内判断condition，返回true时删除
+	DeleteIf(key K, condition func(value any) bool) (deleted bool)
+}
+
 // NewShardMap 实例化ShardMap
 func NewShardMap[K Key]() ShardMap[K] {
+	return NewAtomicShardMap[K]()
+}
+
+// NewAtomicShardMap 实例化AtomicShardMap
+func NewAtomicShardMap[K Key]() AtomicShardMap[K] {
 	m := &shardMap[K]{}
 	for index := 0; index <= shardSize; index++ {
-		m.shardList[index] = NewShard[K](4)
+		m.shardList[index] = NewAtomicShard[K](4)
 	}
 
 	return m
 }
 
 type shardMap[K Key] struct {
-	shardList [shardSize + 1]Shard[K]
+	shardList [shardSize + 1]AtomicShard[K]
 	length    atomic.Int64
 }
 
@@ -57,6 +71,15 @@ func (m *shardMap[K]) Get(key K) (value any, exists bool) {
 	return m.shardList[m.index(key)].Get(key)
 }
 
+func (m *shardMap[K]) GetOrSet(key K, value any) (actual any, loaded bool) {
+	actual, loaded = m.shardList[m.index(key)].GetOrSet(key, value)
+	if !loaded {
+		m.length.Add(1)
+	}
+
+	return
+}
+
 func (m *shardMap[K]) Exists(key K) (exists bool) {
 	return m.shardList[m.index(key)].Exists(key)
 }
@@ -72,6 +95,14 @@ func (m *shardMap[K]) Delete(keys ...K) (delNum int) {
 	return delNum
 }
 
+func (m *shardMap[K]) DeleteIf(key K, condition func(value any) bool) (deleted bool) {
+	if deleted = m.shardList[m.index(key)].DeleteIf(key, condition); deleted {
+		m.length.Sub(1)
+	}
+
+	return
+}
+
 func (m *shardMap[K]) Length() int64 {
 	return m.length.Load()
 }
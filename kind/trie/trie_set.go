@@ -120,6 +120,31 @@ func (ts *Set) ReplaceKey(words string, starChar byte) (newWords string) {
 	return string(data)
 }
 
+// MatchAt 从data[start]开始匹配最长的key，skip返回true的字符在key的字符之间会被跳过，返回匹配结束位置(不含)，没有匹配时返回-1
+func (ts *Set) MatchAt(data []rune, start int, skip func(r rune) bool) (end int) {
+	end = -1
+	current := ts.sub
+
+	for index := start; index < len(data); index++ {
+		r := data[index]
+
+		nd, exists := current.sub[r]
+		if !exists {
+			if index > start && skip != nil && skip(r) {
+				continue
+			}
+			break
+		}
+
+		current = nd
+		if current.isEnd {
+			end = index + 1
+		}
+	}
+
+	return end
+}
+
 func (ts *Set) Length() int64 {
 	return ts.length
 }
@@ -72,3 +72,26 @@ func TestNewTrieSet(t *testing.T) {
 	rd := ts.ReplaceKey(`当时我看见有好多中国人`, '*')
 	t.Logf("rd: %s", rd)
 }
+
+func TestSet_MatchAt(t *testing.T) {
+	ts := NewTrieSet()
+	ts.Add("ab")
+	ts.Add("abcd")
+
+	data := []rune("xa-b c-dy")
+	skip := func(r rune) bool {
+		return r == '-' || r == ' '
+	}
+
+	if end := ts.MatchAt(data, 1, skip); end != 8 {
+		t.Fatalf("want 8, got %d", end)
+	}
+
+	if end := ts.MatchAt(data, 1, nil); end != -1 {
+		t.Fatalf("want -1, got %d", end)
+	}
+
+	if end := ts.MatchAt(data, 0, skip); end != -1 {
+		t.Fatalf("want -1, got %d", end)
+	}
+}
@@ -0,0 +1,58 @@
+package retry
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff 计算第attempt次重试前的等待时间，attempt从1开始，last为上一次的等待时间
+type Backoff interface {
+	Next(attempt int, last time.Duration) time.Duration
+}
+
+// BackoffFunc 函数形式的Backoff
+type BackoffFunc func(attempt int, last time.Duration) time.Duration
+
+func (bf BackoffFunc) Next(attempt int, last time.Duration) time.Duration {
+	return bf(attempt, last)
+}
+
+// Constant 固定间隔
+func Constant(interval time.Duration) Backoff {
+	return BackoffFunc(func(attempt int, last time.Duration) time.Duration {
+		return interval
+	})
+}
+
+// Exponential 指数退避，base*2^(attempt-1)，不超过max
+func Exponential(base, max time.Duration) Backoff {
+	return BackoffFunc(func(attempt int, last time.Duration) time.Duration {
+		delay := float64(base) * math.Pow(2, float64(attempt-1))
+		if delay > float64(max) {
+			return max
+		}
+
+		return time.Duration(delay)
+	})
+}
+
+// DecorrelatedJitter 去相关抖动退避，在[base, last*3)内随机，不超过max，可以避免大量客户端同时重试
+func DecorrelatedJitter(base, max time.Duration) Backoff {
+	return BackoffFunc(func(attempt int, last time.Duration) time.Duration {
+		if last < base {
+			last = base
+		}
+
+		upper := last * 3
+		if upper > max || upper <= 0 {
+			upper = max
+		}
+
+		if upper <= base {
+			return base
+		}
+
+		return base + time.Duration(rand.Int63n(int64(upper-base)))
+	})
+}
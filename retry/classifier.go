@@ -0,0 +1,80 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+
+	"github.com/grpc-boot/base/v3/status"
+)
+
+type permanentError struct {
+	err error
+}
+
+func (pe *permanentError) Error() string {
+	return pe.err.Error()
+}
+
+func (pe *permanentError) Unwrap() error {
+	return pe.err
+}
+
+// Permanent 包装为不可重试的错误
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// IsRetryable 默认的可重试错误判断：
+// status.Status的Unavailable、ResourceExhausted、DeadlineExceeded，网络超时和连接错误，
+// 实现了Retryable() bool的错误按其返回值；Permanent包装的错误和context.Canceled不重试
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pe *permanentError
+	if errors.As(err, &pe) {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var r interface{ Retryable() bool }
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+
+	var st *status.Status
+	if errors.As(err, &st) {
+		switch st.Code {
+		case status.ErrUnavailable, status.ErrResourceExhausted, status.ErrDeadlineExceeded:
+			return true
+		}
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+
+	var oe *net.OpError
+	return errors.As(err, &oe)
+}
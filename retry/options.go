@@ -0,0 +1,66 @@
+package retry
+
+import "time"
+
+var (
+	defaultOptions = func() *Options {
+		return &Options{
+			maxAttempts: 3,
+			backoff:     Exponential(time.Millisecond*100, time.Second*10),
+			retryable:   IsRetryable,
+		}
+	}
+)
+
+type Options struct {
+	maxAttempts int
+	maxElapsed  time.Duration
+	backoff     Backoff
+	retryable   func(err error) bool
+	onRetry     func(attempt int, err error, delay time.Duration)
+}
+
+type Option func(opts *Options)
+
+func loadOptions(options ...Option) *Options {
+	opts := defaultOptions()
+	for _, option := range options {
+		option(opts)
+	}
+	return opts
+}
+
+// WithMaxAttempts 最大执行次数，包含第一次，<=0表示不限制，默认3
+func WithMaxAttempts(attempts int) Option {
+	return func(opts *Options) {
+		opts.maxAttempts = attempts
+	}
+}
+
+// WithMaxElapsed 从第一次执行开始的最长耗时，下一次等待会超过该时间时不再重试，0表示不限制
+func WithMaxElapsed(elapsed time.Duration) Option {
+	return func(opts *Options) {
+		opts.maxElapsed = elapsed
+	}
+}
+
+// WithBackoff 退避策略，默认Exponential(100ms, 10s)
+func WithBackoff(backoff Backoff) Option {
+	return func(opts *Options) {
+		opts.backoff = backoff
+	}
+}
+
+// WithRetryable 判断错误是否可以重试，默认IsRetryable
+func WithRetryable(retryable func(err error) bool) Option {
+	return func(opts *Options) {
+		opts.retryable = retryable
+	}
+}
+
+// WithOnRetry 每次重试等待之前调用，可以用于记录日志
+func WithOnRetry(onRetry func(attempt int, err error, delay time.Duration)) Option {
+	return func(opts *Options) {
+		opts.onRetry = onRetry
+	}
+}
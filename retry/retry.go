@@ -0,0 +1,91 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Policy 重试策略，可以在多个goroutine中复用
+type Policy struct {
+	opts *Options
+}
+
+// New 实例化重试策略
+func New(opts ...Option) *Policy {
+	return &Policy{
+		opts: loadOptions(opts...),
+	}
+}
+
+// Do 执行fn直到成功、错误不可重试、达到次数或耗时上限、ctx结束
+func (p *Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	_, err := DoValue(ctx, p, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// Do 使用opts执行fn
+func Do(ctx context.Context, fn func(ctx context.Context) error, opts ...Option) error {
+	return New(opts...).Do(ctx, fn)
+}
+
+// DoValue 执行有返回值的fn，返回最后一次执行的结果，ctx结束时返回的错误同时包含ctx.Err()和最后一次的错误
+func DoValue[T any](ctx context.Context, p *Policy, fn func(ctx context.Context) (T, error)) (value T, err error) {
+	var (
+		opts  = p.opts
+		start = time.Now()
+		delay time.Duration
+		timer *time.Timer
+	)
+
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for attempt := 1; ; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			if err == nil {
+				return value, ctxErr
+			}
+			return value, errors.Join(ctxErr, err)
+		}
+
+		value, err = fn(ctx)
+		if err == nil || !opts.retryable(err) {
+			return value, err
+		}
+
+		if opts.maxAttempts > 0 && attempt >= opts.maxAttempts {
+			return value, err
+		}
+
+		delay = opts.backoff.Next(attempt, delay)
+		if opts.maxElapsed > 0 && time.Since(start)+delay > opts.maxElapsed {
+			return value, err
+		}
+
+		if opts.onRetry != nil {
+			opts.onRetry(attempt, err, delay)
+		}
+
+		if delay <= 0 {
+			continue
+		}
+
+		if timer == nil {
+			timer = time.NewTimer(delay)
+		} else {
+			timer.Reset(delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return value, errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
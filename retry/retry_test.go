@@ -0,0 +1,139 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/grpc-boot/base/v3/status"
+)
+
+func TestBackoff(t *testing.T) {
+	exp := Exponential(time.Millisecond*10, time.Millisecond*50)
+	for attempt, want := range []time.Duration{10, 20, 40, 50, 50} {
+		if got := exp.Next(attempt+1, 0); got != want*time.Millisecond {
+			t.Fatalf("want %v, got %v", want*time.Millisecond, got)
+		}
+	}
+
+	if got := Constant(time.Second).Next(5, 0); got != time.Second {
+		t.Fatalf("want 1s, got %v", got)
+	}
+
+	var (
+		jitter = DecorrelatedJitter(time.Millisecond*10, time.Millisecond*100)
+		last   time.Duration
+	)
+
+	for attempt := 1; attempt < 100; attempt++ {
+		delay := jitter.Next(attempt, last)
+		upper := last * 3
+		if upper < time.Millisecond*10 {
+			upper = time.Millisecond * 30
+		}
+
+		if delay < time.Millisecond*10 || delay > time.Millisecond*100 || delay > upper {
+			t.Fatalf("want [10ms, %v], got %v", upper, delay)
+		}
+		last = delay
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("unknown"), false},
+		{status.StatusError(status.ErrUnavailable, 0), true},
+		{fmt.Errorf("wrap: %w", status.StatusError(status.ErrResourceExhausted, 0)), true},
+		{status.StatusError(status.ErrDeadlineExceeded, 0), true},
+		{status.StatusError(status.ErrArgument, 0), false},
+		{context.DeadlineExceeded, true},
+		{context.Canceled, false},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{Permanent(status.StatusError(status.ErrUnavailable, 0)), false},
+	}
+
+	for _, c := range cases {
+		if got := IsRetryable(c.err); got != c.want {
+			t.Fatalf("%v: want %v, got %v", c.err, c.want, got)
+		}
+	}
+}
+
+func TestDo(t *testing.T) {
+	var (
+		attempts int
+		retries  []int
+		policy   = New(
+			WithMaxAttempts(5),
+			WithBackoff(Constant(time.Millisecond)),
+			WithOnRetry(func(attempt int, err error, delay time.Duration) {
+				retries = append(retries, attempt)
+			}),
+		)
+	)
+
+	value, err := DoValue(context.Background(), policy, func(ctx context.Context) (int, error) {
+		attempts++
+		if attempts < 3 {
+			return 0, status.StatusError(status.ErrUnavailable, 0)
+		}
+		return attempts, nil
+	})
+
+	if err != nil || value != 3 || len(retries) != 2 {
+		t.Fatalf("want 3, got %d %v %v", value, err, retries)
+	}
+
+	// 不可重试的错误立即返回
+	attempts = 0
+	err = policy.Do(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return status.StatusError(status.ErrArgument, 0)
+	})
+
+	if attempts != 1 || err == nil {
+		t.Fatalf("want 1, got %d %v", attempts, err)
+	}
+
+	// 达到最大次数
+	attempts = 0
+	err = Do(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return context.DeadlineExceeded
+	}, WithMaxAttempts(4), WithBackoff(Constant(0)))
+
+	if attempts != 4 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want 4, got %d %v", attempts, err)
+	}
+}
+
+func TestDoElapsedAndCancel(t *testing.T) {
+	attempts := 0
+	err := Do(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return context.DeadlineExceeded
+	}, WithMaxAttempts(0), WithBackoff(Constant(time.Millisecond*20)), WithMaxElapsed(time.Millisecond*50))
+
+	if attempts != 3 || err == nil {
+		t.Fatalf("want 3, got %d %v", attempts, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*30)
+	defer cancel()
+
+	unavailable := status.StatusError(status.ErrUnavailable, 0)
+	err = Do(ctx, func(ctx context.Context) error {
+		return unavailable
+	}, WithMaxAttempts(0), WithBackoff(Constant(time.Millisecond*10)))
+
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, unavailable) {
+		t.Fatalf("want deadline and unavailable, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package socket
+
+import (
+	"net"
+
+	"github.com/grpc-boot/base/v3/components"
+	"github.com/grpc-boot/base/v3/connctx"
+
+	"go.uber.org/atomic"
+)
+
+const (
+	EventConnect = `socket.connect`
+	EventClose   = `socket.close`
+)
+
+var (
+	connId atomic.Uint64
+)
+
+// Conn 连接，Send是并发安全的
+type Conn interface {
+	// Id 连接id，进程内唯一
+	Id() uint64
+	// Ctx 连接级别的上下文，连接关闭后释放
+	Ctx() connctx.Context
+	// RemoteAddr 对端地址
+	RemoteAddr() net.Addr
+	// Send 编码后放入写队列
+	Send(pkg *components.Package) error
+	// SendFrame 将已按连接的编码方式编码的消息放入写队列
+	SendFrame(frame []byte) error
+	// Close 写完队列中的帧后关闭连接
+	Close() error
+}
+
+func nextConnId() uint64 {
+	return connId.Inc()
+}
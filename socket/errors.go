@@ -0,0 +1,9 @@
+package socket
+
+import "errors"
+
+var (
+	ErrServerClosed   = errors.New("socket: server closed")
+	ErrConnClosed     = errors.New("socket: connection closed")
+	ErrWriteQueueFull = errors.New("socket: write queue is full")
+)
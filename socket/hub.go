@@ -0,0 +1,165 @@
+package socket
+
+import (
+	"sync"
+
+	"github.com/grpc-boot/base/v3/components"
+)
+
+// Hub 管理连接和分组
+type Hub struct {
+	mutex  sync.RWMutex
+	encode func(pkg *components.Package) ([]byte, error)
+	conns  map[uint64]Conn
+	groups map[string]map[uint64]Conn
+	joined map[uint64]map[string]struct{}
+}
+
+func newHub(encode func(pkg *components.Package) ([]byte, error)) *Hub {
+	return &Hub{
+		encode: encode,
+		conns:  map[uint64]Conn{},
+		groups: map[string]map[uint64]Conn{},
+		joined: map[uint64]map[string]struct{}{},
+	}
+}
+
+func (h *Hub) add(conn Conn) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.conns[conn.Id()] = conn
+}
+
+func (h *Hub) remove(conn Conn) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	id := conn.Id()
+	for group := range h.joined[id] {
+		h.leave(group, id)
+	}
+
+	delete(h.joined, id)
+	delete(h.conns, id)
+}
+
+// Conn 根据id获取连接
+func (h *Hub) Conn(id uint64) (conn Conn, exists bool) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	conn, exists = h.conns[id]
+	return
+}
+
+// Count 连接数
+func (h *Hub) Count() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return len(h.conns)
+}
+
+// Range 遍历连接，返回false时停止
+func (h *Hub) Range(fn func(conn Conn) bool) {
+	for _, conn := range h.snapshot("") {
+		if !fn(conn) {
+			return
+		}
+	}
+}
+
+// Join 加入分组，连接关闭时自动退出所有分组
+func (h *Hub) Join(group string, conn Conn) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	id := conn.Id()
+	if _, exists := h.conns[id]; !exists {
+		return
+	}
+
+	members, exists := h.groups[group]
+	if !exists {
+		members = map[uint64]Conn{}
+		h.groups[group] = members
+	}
+	members[id] = conn
+
+	groups, exists := h.joined[id]
+	if !exists {
+		groups = map[string]struct{}{}
+		h.joined[id] = groups
+	}
+	groups[group] = struct{}{}
+}
+
+// Leave 退出分组
+func (h *Hub) Leave(group string, conn Conn) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	id := conn.Id()
+	h.leave(group, id)
+	delete(h.joined[id], group)
+}
+
+func (h *Hub) leave(group string, id uint64) {
+	members, exists := h.groups[group]
+	if !exists {
+		return
+	}
+
+	delete(members, id)
+	if len(members) == 0 {
+		delete(h.groups, group)
+	}
+}
+
+// GroupCount 分组内的连接数
+func (h *Hub) GroupCount(group string) int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return len(h.groups[group])
+}
+
+// Broadcast 向分组内的连接发送Package，只编码一次，返回成功放入写队列的连接数
+func (h *Hub) Broadcast(group string, pkg *components.Package) (sent int, err error) {
+	frame, err := h.encode(pkg)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, conn := range h.snapshot(group) {
+		if conn.SendFrame(frame) == nil {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// BroadcastAll 向所有连接发送Package
+func (h *Hub) BroadcastAll(pkg *components.Package) (sent int, err error) {
+	return h.Broadcast("", pkg)
+}
+
+// snapshot group为空时返回所有连接
+func (h *Hub) snapshot(group string) []Conn {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	members := h.conns
+	if group != "" {
+		members = h.groups[group]
+	}
+
+	list := make([]Conn, 0, len(members))
+	for _, conn := range members {
+		list = append(list, conn)
+	}
+
+	return list
+}
@@ -0,0 +1,124 @@
+package socket
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grpc-boot/base/v3/components"
+	"github.com/grpc-boot/base/v3/gopool"
+)
+
+var (
+	defaultOptions = func() *Options {
+		return &Options{
+			codec:             components.DefaultPackageCodec,
+			idleTimeout:       time.Second * 90,
+			heartbeatInterval: time.Second * 30,
+			writeTimeout:      time.Second * 10,
+			writeQueueSize:    256,
+			maxMessageSize:    components.DefaultMaxFrameSize,
+			compression:       true,
+		}
+	}
+)
+
+type Options struct {
+	codec             *components.PackageCodec
+	idleTimeout       time.Duration
+	heartbeatInterval time.Duration
+	writeTimeout      time.Duration
+	writeQueueSize    int
+	maxConnNum        int
+	pool              *gopool.Pool
+	checkOrigin       func(r *http.Request) bool
+	maxMessageSize    int
+	compression       bool
+	textMessage       bool
+}
+
+type Option func(opts *Options)
+
+func loadOptions(options ...Option) *Options {
+	opts := defaultOptions()
+	for _, option := range options {
+		option(opts)
+	}
+	return opts
+}
+
+// WithCodec 帧编解码，默认components.DefaultPackageCodec
+func WithCodec(codec *components.PackageCodec) Option {
+	return func(opts *Options) {
+		opts.codec = codec
+	}
+}
+
+// WithIdleTimeout 超过该时间没有收到任何帧则关闭连接，0表示不超时
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.idleTimeout = timeout
+	}
+}
+
+// WithHeartbeatInterval 服务端发送心跳的间隔，0表示不发送
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.heartbeatInterval = interval
+	}
+}
+
+// WithWriteTimeout 单帧写超时
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.writeTimeout = timeout
+	}
+}
+
+// WithWriteQueueSize 每个连接的写队列长度，队列满时Send返回ErrWriteQueueFull
+func WithWriteQueueSize(size int) Option {
+	return func(opts *Options) {
+		opts.writeQueueSize = size
+	}
+}
+
+// WithMaxConnNum 最大连接数，0表示不限制
+func WithMaxConnNum(num int) Option {
+	return func(opts *Options) {
+		opts.maxConnNum = num
+	}
+}
+
+// WithPool 使用协程池执行handler，默认在连接的读协程中顺序执行
+func WithPool(pool *gopool.Pool) Option {
+	return func(opts *Options) {
+		opts.pool = pool
+	}
+}
+
+// WithCheckOrigin WebSocket握手时校验Origin，返回false时拒绝连接，默认不校验
+func WithCheckOrigin(checkOrigin func(r *http.Request) bool) Option {
+	return func(opts *Options) {
+		opts.checkOrigin = checkOrigin
+	}
+}
+
+// WithMaxMessageSize WebSocket消息（合并分片并解压后）的最大长度
+func WithMaxMessageSize(size int) Option {
+	return func(opts *Options) {
+		opts.maxMessageSize = size
+	}
+}
+
+// WithCompression 是否协商permessage-deflate，默认开启
+func WithCompression(enable bool) Option {
+	return func(opts *Options) {
+		opts.compression = enable
+	}
+}
+
+// WithTextMessage WebSocket使用JSON文本消息发送Package，默认使用二进制帧
+func WithTextMessage(enable bool) Option {
+	return func(opts *Options) {
+		opts.textMessage = enable
+	}
+}
@@ -0,0 +1,74 @@
+package socket
+
+import (
+	"sync"
+
+	"github.com/grpc-boot/base/v3/components"
+	"github.com/grpc-boot/base/v3/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	PingName = `sys.ping`
+	PongName = `sys.pong`
+)
+
+// Handler 处理Package
+type Handler func(conn Conn, pkg *components.Package)
+
+// Router 根据Package.Name分发到handler，TCP和WebSocket共用
+type Router struct {
+	mutex    sync.RWMutex
+	handlers map[string]Handler
+	notFound Handler
+}
+
+// NewRouter 实例化Router
+func NewRouter() *Router {
+	return &Router{
+		handlers: map[string]Handler{},
+	}
+}
+
+// Handle 注册handler，已存在的name会被替换
+func (r *Router) Handle(name string, handler Handler) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.handlers[name] = handler
+}
+
+// NotFound 没有匹配的handler时调用
+func (r *Router) NotFound(handler Handler) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.notFound = handler
+}
+
+// Dispatch 分发Package，handler的panic会被捕获并记录日志
+func (r *Router) Dispatch(conn Conn, pkg *components.Package) {
+	r.mutex.RLock()
+	handler, exists := r.handlers[pkg.Name]
+	if !exists {
+		handler = r.notFound
+	}
+	r.mutex.RUnlock()
+
+	if handler == nil {
+		return
+	}
+
+	defer func() {
+		if err := recover(); err != nil {
+			logger.Error("socket handler panic",
+				zap.String("Name", pkg.Name),
+				zap.Uint64("ConnId", conn.Id()),
+				zap.Any("Error", err),
+			)
+		}
+	}()
+
+	handler(conn, pkg)
+}
@@ -0,0 +1,353 @@
+package socket
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/grpc-boot/base/v3/components"
+	"github.com/grpc-boot/base/v3/connctx"
+	"github.com/grpc-boot/base/v3/logger"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+// TcpServer 基于Package帧的TCP服务，实现了grace.Serve
+type TcpServer struct {
+	*Hub
+
+	opts      *Options
+	router    *Router
+	events    components.EventManager
+	mutex     sync.Mutex
+	listeners map[net.Listener]struct{}
+	closing   atomic.Bool
+	wg        sync.WaitGroup
+	pingFrame []byte
+	pongFrame []byte
+}
+
+// NewTcpServer 实例化TcpServer
+func NewTcpServer(router *Router, opts ...Option) *TcpServer {
+	options := loadOptions(opts...)
+
+	s := &TcpServer{
+		Hub:       newHub(options.codec.Encode),
+		opts:      options,
+		router:    router,
+		listeners: map[net.Listener]struct{}{},
+	}
+
+	s.pingFrame, _ = options.codec.Encode(&components.Package{Name: PingName})
+	s.pongFrame, _ = options.codec.Encode(&components.Package{Name: PongName})
+	return s
+}
+
+// On 注册连接事件EventConnect、EventClose，事件数据为Conn，需要在Serve之前调用
+func (s *TcpServer) On(name string, handlers ...components.Handler) {
+	s.events.On(name, handlers...)
+}
+
+// Serve 接受连接，直到ShutdownWithContext被调用
+func (s *TcpServer) Serve(ln net.Listener) error {
+	if !s.trackListener(ln, true) {
+		return ErrServerClosed
+	}
+	defer s.trackListener(ln, false)
+
+	var delay time.Duration
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if s.closing.Load() {
+				return ErrServerClosed
+			}
+
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				if delay == 0 {
+					delay = 5 * time.Millisecond
+				} else if delay *= 2; delay > time.Second {
+					delay = time.Second
+				}
+
+				time.Sleep(delay)
+				continue
+			}
+
+			return err
+		}
+
+		delay = 0
+		if s.opts.maxConnNum > 0 && s.Count() >= s.opts.maxConnNum {
+			_ = conn.Close()
+			continue
+		}
+
+		// 与ShutdownWithContext使用同一把锁，保证wg.Add不会与wg.Wait并发
+		s.mutex.Lock()
+		if s.closing.Load() {
+			s.mutex.Unlock()
+			_ = conn.Close()
+			return ErrServerClosed
+		}
+		s.wg.Add(1)
+		s.mutex.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// ServeTLS 使用证书接受TLS连接
+func (s *TcpServer) ServeTLS(ln net.Listener, certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(tls.NewListener(ln, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}))
+}
+
+// ShutdownWithContext 停止接受连接，关闭所有连接并等待写队列发送完毕，ctx结束时强制关闭
+func (s *TcpServer) ShutdownWithContext(ctx context.Context) (err error) {
+	s.mutex.Lock()
+	s.closing.Store(true)
+	for ln := range s.listeners {
+		if e := ln.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	s.mutex.Unlock()
+
+	s.Range(func(conn Conn) bool {
+		_ = conn.Close()
+		return true
+	})
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		s.Range(func(conn Conn) bool {
+			if c, ok := conn.(*tcpConn); ok {
+				_ = c.conn.Close()
+			}
+			return true
+		})
+		return ctx.Err()
+	}
+}
+
+func (s *TcpServer) trackListener(ln net.Listener, add bool) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if add {
+		if s.closing.Load() {
+			return false
+		}
+		s.listeners[ln] = struct{}{}
+	} else {
+		delete(s.listeners, ln)
+	}
+
+	return true
+}
+
+func (s *TcpServer) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+
+	c := &tcpConn{
+		id:         nextConnId(),
+		conn:       conn,
+		ctx:        connctx.AcquireCtx(),
+		server:     s,
+		queue:      make(chan []byte, s.opts.writeQueueSize),
+		done:       make(chan struct{}),
+		writerDone: make(chan struct{}),
+	}
+
+	s.add(c)
+	go c.writeLoop()
+
+	// 关闭过程中接入的连接直接关闭
+	if s.closing.Load() {
+		_ = c.Close()
+	}
+
+	s.events.Trigger(EventConnect, c)
+
+	c.readLoop()
+	_ = c.Close()
+	<-c.writerDone
+
+	s.remove(c)
+	s.events.Trigger(EventClose, c)
+	c.handlers.Wait()
+	c.ctx.Close()
+}
+
+type tcpConn struct {
+	id         uint64
+	conn       net.Conn
+	ctx        connctx.Context
+	server     *TcpServer
+	queue      chan []byte
+	done       chan struct{}
+	writerDone chan struct{}
+	closeOnce  sync.Once
+	// handlers 协程池中未执行完的handler，全部结束后才能回收ctx
+	handlers sync.WaitGroup
+}
+
+func (c *tcpConn) Id() uint64 {
+	return c.id
+}
+
+func (c *tcpConn) Ctx() connctx.Context {
+	return c.ctx
+}
+
+func (c *tcpConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+func (c *tcpConn) Send(pkg *components.Package) error {
+	frame, err := c.server.opts.codec.Encode(pkg)
+	if err != nil {
+		return err
+	}
+
+	return c.SendFrame(frame)
+}
+
+func (c *tcpConn) SendFrame(frame []byte) error {
+	select {
+	case <-c.done:
+		return ErrConnClosed
+	default:
+	}
+
+	select {
+	case c.queue <- frame:
+		return nil
+	default:
+		return ErrWriteQueueFull
+	}
+}
+
+func (c *tcpConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+	return nil
+}
+
+func (c *tcpConn) readLoop() {
+	var (
+		opts   = c.server.opts
+		reader = components.NewPackageReader(c.conn, opts.codec)
+	)
+
+	for {
+		if opts.idleTimeout > 0 {
+			_ = c.conn.SetReadDeadline(time.Now().Add(opts.idleTimeout))
+		}
+
+		pkg, err := reader.Read()
+		if err != nil {
+			return
+		}
+
+		switch pkg.Name {
+		case PingName:
+			_ = c.SendFrame(c.server.pongFrame)
+			continue
+		case PongName:
+			continue
+		}
+
+		if opts.pool == nil {
+			c.server.router.Dispatch(c, pkg)
+			continue
+		}
+
+		c.handlers.Add(1)
+		if err = opts.pool.Submit(func() {
+			defer c.handlers.Done()
+			c.server.router.Dispatch(c, pkg)
+		}); err != nil {
+			c.handlers.Done()
+			logger.Error("socket submit handler failed",
+				zap.String("Name", pkg.Name),
+				zap.NamedError("Error", err),
+			)
+		}
+	}
+}
+
+func (c *tcpConn) writeLoop() {
+	defer close(c.writerDone)
+	defer c.conn.Close()
+
+	var (
+		opts      = c.server.opts
+		heartbeat <-chan time.Time
+	)
+
+	if opts.heartbeatInterval > 0 {
+		ticker := time.NewTicker(opts.heartbeatInterval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case frame := <-c.queue:
+			if !c.write(frame) {
+				return
+			}
+		case <-heartbeat:
+			if !c.write(c.server.pingFrame) {
+				return
+			}
+		case <-c.done:
+			// 发送队列中剩余的帧
+			for {
+				select {
+				case frame := <-c.queue:
+					if !c.write(frame) {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *tcpConn) write(frame []byte) bool {
+	if c.server.opts.writeTimeout > 0 {
+		_ = c.conn.SetWriteDeadline(time.Now().Add(c.server.opts.writeTimeout))
+	}
+
+	if _, err := c.conn.Write(frame); err != nil {
+		_ = c.Close()
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,275 @@
+package socket
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/grpc-boot/base/v3/components"
+	"github.com/grpc-boot/base/v3/gopool"
+	"github.com/grpc-boot/base/v3/kind"
+)
+
+func serveTcp(t *testing.T, server *TcpServer) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	go func() {
+		_ = server.Serve(ln)
+	}()
+
+	return ln.Addr().String()
+}
+
+func dialTcp(t *testing.T, addr string) (net.Conn, *components.PackageReader, *components.PackageWriter) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(time.Second * 5))
+	return conn, components.NewPackageReader(conn, nil), components.NewPackageWriter(conn, nil)
+}
+
+func TestTcpServer(t *testing.T) {
+	router := NewRouter()
+	router.Handle("echo", func(conn Conn, pkg *components.Package) {
+		count, _ := conn.Ctx().Incr("count")
+		pkg.Param["count"] = count
+		_ = conn.Send(pkg)
+	})
+	router.Handle("join", func(conn Conn, pkg *components.Package) {
+		server, _ := conn.Ctx().Get("server")
+		server.(*TcpServer).Join(pkg.Param.String("group"), conn)
+		_ = conn.Send(&components.Package{Name: "joined"})
+	})
+	router.Handle("panic", func(conn Conn, pkg *components.Package) {
+		panic("boom")
+	})
+
+	server := NewTcpServer(router, WithHeartbeatInterval(0))
+	server.On(EventConnect, func(ctx *components.Context) {
+		ctx.Event().Data().(Conn).Ctx().Set("server", server)
+	})
+	addr := serveTcp(t, server)
+
+	conn, reader, writer := dialTcp(t, addr)
+	defer conn.Close()
+
+	// 心跳
+	_ = writer.Write(&components.Package{Name: PingName})
+	pkg, err := reader.Read()
+	if err != nil || pkg.Name != PongName {
+		t.Fatalf("want %s, got %v %v", PongName, pkg, err)
+	}
+
+	// panic不影响连接
+	_ = writer.Write(&components.Package{Name: "panic"})
+
+	for i := 1; i <= 3; i++ {
+		_ = writer.Write(&components.Package{Id: uint16(i), Name: "echo", Param: kind.JsonParam{"seq": i}})
+		pkg, err = reader.Read()
+		if err != nil || pkg.Id != uint16(i) || pkg.Param.Int("count") != i {
+			t.Fatalf("want %d, got %v %v", i, pkg, err)
+		}
+	}
+
+	// 分组广播
+	other, otherReader, otherWriter := dialTcp(t, addr)
+	defer other.Close()
+
+	for _, w := range []*components.PackageWriter{writer, otherWriter} {
+		_ = w.Write(&components.Package{Name: "join", Param: kind.JsonParam{"group": "room"}})
+	}
+
+	for _, r := range []*components.PackageReader{reader, otherReader} {
+		if pkg, err = r.Read(); err != nil || pkg.Name != "joined" {
+			t.Fatalf("want joined, got %v %v", pkg, err)
+		}
+	}
+
+	sent, err := server.Broadcast("room", &components.Package{Name: "notice"})
+	if err != nil || sent != 2 {
+		t.Fatalf("want 2, got %d %v", sent, err)
+	}
+
+	for _, r := range []*components.PackageReader{reader, otherReader} {
+		if pkg, err = r.Read(); err != nil || pkg.Name != "notice" {
+			t.Fatalf("want notice, got %v %v", pkg, err)
+		}
+	}
+
+	// 关闭后自动退出分组
+	_ = other.Close()
+	deadline := time.Now().Add(time.Second)
+	for server.GroupCount("room") != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	if count := server.GroupCount("room"); count != 1 {
+		t.Fatalf("want 1, got %d", count)
+	}
+}
+
+func TestTcpServerPoolCtx(t *testing.T) {
+	pool, err := gopool.NewPool(4)
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+		result  = make(chan any, 1)
+		closed  = make(chan struct{})
+		router  = NewRouter()
+	)
+
+	router.Handle("slow", func(conn Conn, pkg *components.Package) {
+		close(started)
+		<-release
+		value, _ := conn.Ctx().Get("user")
+		result <- value
+	})
+
+	server := NewTcpServer(router, WithPool(pool), WithHeartbeatInterval(0))
+	server.On(EventConnect, func(ctx *components.Context) {
+		ctx.Event().Data().(Conn).Ctx().Set("user", "u1")
+	})
+	server.On(EventClose, func(ctx *components.Context) {
+		close(closed)
+	})
+	addr := serveTcp(t, server)
+
+	conn, _, writer := dialTcp(t, addr)
+	_ = writer.Write(&components.Package{Name: "slow"})
+	<-started
+	_ = conn.Close()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatalf("want closed")
+	}
+
+	// 对端断开后handler仍在执行，ctx不能被回收
+	close(release)
+	if value := <-result; value != "u1" {
+		t.Fatalf("want u1, got %v", value)
+	}
+}
+
+func TestTcpServerIdleTimeout(t *testing.T) {
+	closed := make(chan uint64, 1)
+	server := NewTcpServer(NewRouter(), WithIdleTimeout(time.Millisecond*100), WithHeartbeatInterval(time.Millisecond*30))
+	server.On(EventClose, func(ctx *components.Context) {
+		closed <- ctx.Event().Data().(Conn).Id()
+	})
+	addr := serveTcp(t, server)
+
+	conn, reader, _ := dialTcp(t, addr)
+	defer conn.Close()
+
+	pkg, err := reader.Read()
+	if err != nil || pkg.Name != PingName {
+		t.Fatalf("want %s, got %v %v", PingName, pkg, err)
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatalf("want closed by idle timeout")
+	}
+}
+
+func TestTcpServerShutdown(t *testing.T) {
+	router := NewRouter()
+	router.Handle("burst", func(conn Conn, pkg *components.Package) {
+		for i := 0; i < 10; i++ {
+			_ = conn.Send(&components.Package{Id: uint16(i), Name: "data"})
+		}
+		_ = conn.Close()
+	})
+
+	server := NewTcpServer(router)
+	addr := serveTcp(t, server)
+	conn, reader, writer := dialTcp(t, addr)
+	defer conn.Close()
+
+	_ = writer.Write(&components.Package{Name: "burst"})
+
+	// Close会先发送写队列中的帧
+	for i := 0; i < 10; i++ {
+		pkg, err := reader.Read()
+		if err != nil || pkg.Id != uint16(i) {
+			t.Fatalf("want %d, got %v %v", i, pkg, err)
+		}
+	}
+
+	if _, err := reader.Read(); err != io.EOF {
+		t.Fatalf("want io.EOF, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := server.ShutdownWithContext(ctx); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if err := server.Serve(nil); err != ErrServerClosed {
+		t.Fatalf("want ErrServerClosed, got %v", err)
+	}
+}
+
+// pipeListener 不断返回新连接，用于在关闭过程中持续接入
+type pipeListener struct {
+	done chan struct{}
+}
+
+func (pl *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case <-pl.done:
+		return nil, net.ErrClosed
+	default:
+	}
+
+	server, client := net.Pipe()
+	_ = client.Close()
+	return server, nil
+}
+
+func (pl *pipeListener) Close() error {
+	close(pl.done)
+	return nil
+}
+
+func (pl *pipeListener) Addr() net.Addr {
+	return &net.TCPAddr{}
+}
+
+func TestTcpServerShutdownAccepting(t *testing.T) {
+	// 关闭过程中仍在接入连接，需要使用-race运行
+	for i := 0; i < 20; i++ {
+		server := NewTcpServer(NewRouter())
+		served := make(chan error, 1)
+		go func() {
+			served <- server.Serve(&pipeListener{done: make(chan struct{})})
+		}()
+
+		time.Sleep(time.Millisecond)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		if err := server.ShutdownWithContext(ctx); err != nil {
+			t.Fatalf("want nil, got %v", err)
+		}
+		cancel()
+
+		if err := <-served; err != ErrServerClosed {
+			t.Fatalf("want ErrServerClosed, got %v", err)
+		}
+	}
+}
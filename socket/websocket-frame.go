@@ -0,0 +1,235 @@
+package socket
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	OpContinuation byte = 0x0
+	OpText         byte = 0x1
+	OpBinary       byte = 0x2
+	OpClose        byte = 0x8
+	OpPing         byte = 0x9
+	OpPong         byte = 0xa
+)
+
+const (
+	CloseNormal             uint16 = 1000
+	CloseGoingAway          uint16 = 1001
+	CloseProtocolError      uint16 = 1002
+	CloseUnsupportedData    uint16 = 1003
+	CloseNoStatus           uint16 = 1005
+	CloseAbnormal           uint16 = 1006
+	CloseInvalidPayload     uint16 = 1007
+	ClosePolicyViolation    uint16 = 1008
+	CloseMessageTooBig      uint16 = 1009
+	CloseMandatoryExtension uint16 = 1010
+	CloseInternalError      uint16 = 1011
+)
+
+const (
+	maxControlPayload   = 125
+	wsCompressThreshold = 128
+)
+
+var (
+	// wsDeflateTail 解压时补上发送端去掉的空块，再追加一个结束块使flate.Reader正常返回EOF
+	wsDeflateTail = []byte{0x00, 0x00, 0xff, 0xff, 0x01, 0x00, 0x00, 0xff, 0xff}
+)
+
+// CloseError WebSocket关闭帧
+type CloseError struct {
+	Code   uint16
+	Reason string
+}
+
+func (ce *CloseError) Error() string {
+	return "socket: websocket close " + strconv.Itoa(int(ce.Code)) + " " + ce.Reason
+}
+
+func protocolError(reason string) *CloseError {
+	return &CloseError{Code: CloseProtocolError, Reason: reason}
+}
+
+type wsFrame struct {
+	fin     bool
+	rsv1    bool
+	opcode  byte
+	payload []byte
+}
+
+// readWsFrame 读取客户端帧，客户端帧必须有掩码
+func readWsFrame(r io.Reader, maxPayload int, compression bool) (frame wsFrame, err error) {
+	var header [8]byte
+	if _, err = io.ReadFull(r, header[:2]); err != nil {
+		return
+	}
+
+	frame.fin = header[0]&0x80 != 0
+	frame.rsv1 = header[0]&0x40 != 0
+	frame.opcode = header[0] & 0x0f
+
+	if header[0]&0x30 != 0 {
+		return frame, protocolError("reserved bits set")
+	}
+
+	if frame.rsv1 && (!compression || (frame.opcode != OpText && frame.opcode != OpBinary)) {
+		return frame, protocolError("unexpected rsv1")
+	}
+
+	if header[1]&0x80 == 0 {
+		return frame, protocolError("client frame must be masked")
+	}
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		if _, err = io.ReadFull(r, header[:2]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(header[:2]))
+	case 127:
+		if _, err = io.ReadFull(r, header[:8]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(header[:8])
+	}
+
+	if frame.opcode >= OpClose && (length > maxControlPayload || !frame.fin) {
+		return frame, protocolError("invalid control frame")
+	}
+
+	if frame.opcode < OpClose && length > uint64(maxPayload) {
+		return frame, &CloseError{Code: CloseMessageTooBig}
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(r, mask[:]); err != nil {
+		return
+	}
+
+	frame.payload = make([]byte, length)
+	if _, err = io.ReadFull(r, frame.payload); err != nil {
+		return
+	}
+
+	for i := range frame.payload {
+		frame.payload[i] ^= mask[i&3]
+	}
+
+	return frame, nil
+}
+
+// appendWsHeader 服务端帧头，服务端帧不使用掩码
+func appendWsHeader(dst []byte, opcode byte, length int, rsv1 bool) []byte {
+	b0 := 0x80 | opcode
+	if rsv1 {
+		b0 |= 0x40
+	}
+
+	switch {
+	case length <= maxControlPayload:
+		return append(dst, b0, byte(length))
+	case length <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(dst, b0, 126), uint16(length))
+	}
+
+	return binary.BigEndian.AppendUint64(append(dst, b0, 127), uint64(length))
+}
+
+// parseClosePayload 解析关闭帧，没有状态码时返回CloseNoStatus
+func parseClosePayload(payload []byte) (*CloseError, error) {
+	if len(payload) == 0 {
+		return &CloseError{Code: CloseNoStatus}, nil
+	}
+
+	if len(payload) == 1 {
+		return nil, protocolError("invalid close payload")
+	}
+
+	code := binary.BigEndian.Uint16(payload)
+	if !validCloseCode(code) {
+		return nil, protocolError("invalid close code")
+	}
+
+	if !utf8.Valid(payload[2:]) {
+		return nil, &CloseError{Code: CloseInvalidPayload}
+	}
+
+	return &CloseError{Code: code, Reason: string(payload[2:])}, nil
+}
+
+func closePayload(code uint16, reason string) []byte {
+	if code == CloseNoStatus {
+		return nil
+	}
+
+	if len(reason) > maxControlPayload-2 {
+		reason = reason[:maxControlPayload-2]
+	}
+
+	return append(binary.BigEndian.AppendUint16(nil, code), reason...)
+}
+
+func validCloseCode(code uint16) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+
+	return false
+}
+
+// wsDecompress 解压permessage-deflate消息，超过limit时返回CloseMessageTooBig
+func wsDecompress(data []byte, limit int) ([]byte, error) {
+	reader := flate.NewReader(io.MultiReader(bytes.NewReader(data), bytes.NewReader(wsDeflateTail)))
+	defer reader.Close()
+
+	out, err := io.ReadAll(io.LimitReader(reader, int64(limit)+1))
+	if err != nil {
+		return nil, &CloseError{Code: CloseInvalidPayload, Reason: "invalid deflate data"}
+	}
+
+	if len(out) > limit {
+		return nil, &CloseError{Code: CloseMessageTooBig}
+	}
+
+	return out, nil
+}
+
+// wsCompressor 发送端压缩，不保留上下文
+type wsCompressor struct {
+	buf    bytes.Buffer
+	writer *flate.Writer
+}
+
+func (wc *wsCompressor) compress(data []byte) ([]byte, error) {
+	wc.buf.Reset()
+	if wc.writer == nil {
+		writer, err := flate.NewWriter(&wc.buf, flate.BestSpeed)
+		if err != nil {
+			return nil, err
+		}
+		wc.writer = writer
+	} else {
+		wc.writer.Reset(&wc.buf)
+	}
+
+	if _, err := wc.writer.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := wc.writer.Flush(); err != nil {
+		return nil, err
+	}
+
+	// 去掉sync flush产生的空块
+	return bytes.TrimSuffix(wc.buf.Bytes(), wsDeflateTail[:4]), nil
+}
@@ -0,0 +1,525 @@
+package socket
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grpc-boot/base/v3/components"
+	"github.com/grpc-boot/base/v3/connctx"
+	"github.com/grpc-boot/base/v3/logger"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+const (
+	wsGuid          = `258EAFA5-E914-47DA-95CA-C5AB0DC85B11`
+	wsDeflateName   = `permessage-deflate`
+	wsDeflateAccept = `permessage-deflate; server_no_context_takeover; client_no_context_takeover`
+)
+
+// WsServer RFC 6455 WebSocket服务，作为http.Handler挂载，消息为Package，与TcpServer共用Router
+// text消息按Package JSON解析，binary消息按PackageCodec帧解析
+type WsServer struct {
+	*Hub
+
+	opts    *Options
+	router  *Router
+	events  components.EventManager
+	mutex   sync.Mutex
+	closing atomic.Bool
+	wg      sync.WaitGroup
+}
+
+// NewWsServer 实例化WsServer
+func NewWsServer(router *Router, opts ...Option) *WsServer {
+	s := &WsServer{
+		opts:   loadOptions(opts...),
+		router: router,
+	}
+
+	s.Hub = newHub(s.encode)
+	return s
+}
+
+// On 注册连接事件EventConnect、EventClose，事件数据为Conn，需要在处理请求之前调用
+func (s *WsServer) On(name string, handlers ...components.Handler) {
+	s.events.On(name, handlers...)
+}
+
+func (s *WsServer) encode(pkg *components.Package) ([]byte, error) {
+	if s.opts.textMessage {
+		return pkg.Pack(), nil
+	}
+
+	return s.opts.codec.Encode(pkg)
+}
+
+// ServeHTTP 握手并在当前goroutine中处理连接直到关闭
+func (s *WsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.closing.Load() {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, br, compression, ok := s.upgrade(w, r)
+	if !ok {
+		return
+	}
+
+	// 与ShutdownWithContext使用同一把锁，保证wg.Add不会与wg.Wait并发
+	s.mutex.Lock()
+	if s.closing.Load() {
+		s.mutex.Unlock()
+		_ = conn.Close()
+		return
+	}
+	s.wg.Add(1)
+	s.mutex.Unlock()
+	defer s.wg.Done()
+
+	c := &wsConn{
+		id:          nextConnId(),
+		conn:        conn,
+		br:          br,
+		ctx:         connctx.AcquireCtx(),
+		server:      s,
+		compression: compression,
+		queue:       make(chan wsMessage, s.opts.writeQueueSize),
+		done:        make(chan struct{}),
+		writerDone:  make(chan struct{}),
+		closeCode:   CloseNormal,
+	}
+
+	s.add(c)
+	go c.writeLoop()
+
+	if s.closing.Load() {
+		c.closeWith(CloseGoingAway, "")
+	}
+
+	s.events.Trigger(EventConnect, c)
+
+	if err := c.readLoop(); err != nil {
+		if ce, ok := err.(*CloseError); ok {
+			c.closeWith(ce.Code, ce.Reason)
+		}
+	}
+
+	_ = c.Close()
+	<-c.writerDone
+
+	s.remove(c)
+	s.events.Trigger(EventClose, c)
+	c.handlers.Wait()
+	c.ctx.Close()
+}
+
+// ShutdownWithContext 拒绝新的握手，向所有连接发送1001关闭帧，ctx结束时强制关闭
+func (s *WsServer) ShutdownWithContext(ctx context.Context) error {
+	s.mutex.Lock()
+	s.closing.Store(true)
+	s.mutex.Unlock()
+
+	s.Range(func(conn Conn) bool {
+		conn.(*wsConn).closeWith(CloseGoingAway, "")
+		return true
+	})
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.Range(func(conn Conn) bool {
+			_ = conn.(*wsConn).conn.Close()
+			return true
+		})
+		return ctx.Err()
+	}
+}
+
+func (s *WsServer) upgrade(w http.ResponseWriter, r *http.Request) (conn net.Conn, br *bufio.Reader, compression bool, ok bool) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "websocket: not a websocket handshake", http.StatusBadRequest)
+		return
+	}
+
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "websocket: unsupported version", http.StatusUpgradeRequired)
+		return
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		http.Error(w, "websocket: invalid Sec-WebSocket-Key", http.StatusBadRequest)
+		return
+	}
+
+	if s.opts.checkOrigin != nil && !s.opts.checkOrigin(r) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	hijacker, isHijacker := w.(http.Hijacker)
+	if !isHijacker {
+		http.Error(w, "websocket: response does not implement http.Hijacker", http.StatusInternalServerError)
+		return
+	}
+
+	compression = s.opts.compression && acceptDeflate(r.Header.Values("Sec-WebSocket-Extensions"))
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		logger.Error("websocket hijack failed", zap.NamedError("Error", err))
+		return
+	}
+
+	var response strings.Builder
+	response.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: ")
+	response.WriteString(acceptKey(key))
+	if compression {
+		response.WriteString("\r\nSec-WebSocket-Extensions: ")
+		response.WriteString(wsDeflateAccept)
+	}
+	response.WriteString("\r\n\r\n")
+
+	_ = conn.SetDeadline(time.Time{})
+	if _, err = conn.Write([]byte(response.String())); err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	return conn, rw.Reader, compression, true
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + wsGuid))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerContains 头部是否包含token，不区分大小写
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, item := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// acceptDeflate 是否有可以接受的permessage-deflate提议，服务端总是使用32K窗口且不保留上下文
+func acceptDeflate(values []string) bool {
+	for _, value := range values {
+		for _, offer := range strings.Split(value, ",") {
+			params := strings.Split(offer, ";")
+			if strings.TrimSpace(params[0]) != wsDeflateName {
+				continue
+			}
+
+			acceptable := true
+			for _, param := range params[1:] {
+				name, arg, _ := strings.Cut(strings.TrimSpace(param), "=")
+				switch strings.TrimSpace(name) {
+				case "server_no_context_takeover", "client_no_context_takeover", "client_max_window_bits":
+				case "server_max_window_bits":
+					acceptable = acceptable && strings.Trim(strings.TrimSpace(arg), `"`) == "15"
+				default:
+					acceptable = false
+				}
+			}
+
+			if acceptable {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type wsMessage struct {
+	opcode byte
+	data   []byte
+}
+
+type wsConn struct {
+	id          uint64
+	conn        net.Conn
+	br          *bufio.Reader
+	ctx         connctx.Context
+	server      *WsServer
+	compression bool
+	compressor  wsCompressor
+	queue       chan wsMessage
+	done        chan struct{}
+	writerDone  chan struct{}
+	closeOnce   sync.Once
+	closeCode   uint16
+	closeReason string
+	// handlers 协程池中未执行完的handler，全部结束后才能回收ctx
+	handlers sync.WaitGroup
+}
+
+func (c *wsConn) Id() uint64 {
+	return c.id
+}
+
+func (c *wsConn) Ctx() connctx.Context {
+	return c.ctx
+}
+
+func (c *wsConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+func (c *wsConn) Send(pkg *components.Package) error {
+	data, err := c.server.encode(pkg)
+	if err != nil {
+		return err
+	}
+
+	return c.SendFrame(data)
+}
+
+func (c *wsConn) SendFrame(frame []byte) error {
+	opcode := OpBinary
+	if c.server.opts.textMessage {
+		opcode = OpText
+	}
+
+	return c.enqueue(wsMessage{opcode: opcode, data: frame})
+}
+
+// Close 写完队列中的消息后发送1000关闭帧
+func (c *wsConn) Close() error {
+	c.closeWith(CloseNormal, "")
+	return nil
+}
+
+func (c *wsConn) closeWith(code uint16, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		c.closeReason = reason
+		close(c.done)
+	})
+}
+
+func (c *wsConn) enqueue(msg wsMessage) error {
+	select {
+	case <-c.done:
+		return ErrConnClosed
+	default:
+	}
+
+	select {
+	case c.queue <- msg:
+		return nil
+	default:
+		return ErrWriteQueueFull
+	}
+}
+
+func (c *wsConn) readLoop() error {
+	var (
+		opts     = c.server.opts
+		opcode   byte
+		deflated bool
+		message  []byte
+	)
+
+	for {
+		if opts.idleTimeout > 0 {
+			_ = c.conn.SetReadDeadline(time.Now().Add(opts.idleTimeout))
+		}
+
+		frame, err := readWsFrame(c.br, opts.maxMessageSize-len(message), c.compression)
+		if err != nil {
+			return err
+		}
+
+		switch frame.opcode {
+		case OpPing:
+			_ = c.enqueue(wsMessage{opcode: OpPong, data: frame.payload})
+			continue
+		case OpPong:
+			continue
+		case OpClose:
+			ce, err := parseClosePayload(frame.payload)
+			if err != nil {
+				return err
+			}
+
+			// 回复相同的状态码
+			if ce.Code == CloseNoStatus {
+				ce.Code = CloseNormal
+			}
+			return ce
+		case OpText, OpBinary:
+			if opcode != 0 {
+				return protocolError("expect continuation frame")
+			}
+			opcode, deflated, message = frame.opcode, frame.rsv1, frame.payload
+		case OpContinuation:
+			if opcode == 0 {
+				return protocolError("unexpected continuation frame")
+			}
+			message = append(message, frame.payload...)
+		default:
+			return protocolError("unknown opcode")
+		}
+
+		if !frame.fin {
+			continue
+		}
+
+		if deflated {
+			if message, err = wsDecompress(message, opts.maxMessageSize); err != nil {
+				return err
+			}
+		}
+
+		if err = c.handle(opcode, message); err != nil {
+			return err
+		}
+
+		opcode, deflated, message = 0, false, nil
+	}
+}
+
+func (c *wsConn) handle(opcode byte, message []byte) error {
+	var (
+		pkg *components.Package
+		err error
+	)
+
+	if opcode == OpText {
+		if !utf8.Valid(message) {
+			return &CloseError{Code: CloseInvalidPayload}
+		}
+
+		pkg = &components.Package{}
+		err = pkg.Unpack(message)
+	} else {
+		pkg, err = c.server.opts.codec.Decode(message)
+	}
+
+	if err != nil {
+		return &CloseError{Code: CloseUnsupportedData, Reason: "invalid package"}
+	}
+
+	switch pkg.Name {
+	case PingName:
+		// 与TcpServer一致，写队列满时丢弃pong，不关闭连接
+		_ = c.Send(&components.Package{Name: PongName})
+		return nil
+	case PongName:
+		return nil
+	}
+
+	if c.server.opts.pool == nil {
+		c.server.router.Dispatch(c, pkg)
+		return nil
+	}
+
+	c.handlers.Add(1)
+	if err = c.server.opts.pool.Submit(func() {
+		defer c.handlers.Done()
+		c.server.router.Dispatch(c, pkg)
+	}); err != nil {
+		c.handlers.Done()
+		logger.Error("socket submit handler failed",
+			zap.String("Name", pkg.Name),
+			zap.NamedError("Error", err),
+		)
+	}
+
+	return nil
+}
+
+func (c *wsConn) writeLoop() {
+	defer close(c.writerDone)
+	defer c.conn.Close()
+
+	var heartbeat <-chan time.Time
+	if c.server.opts.heartbeatInterval > 0 {
+		ticker := time.NewTicker(c.server.opts.heartbeatInterval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case msg := <-c.queue:
+			if !c.write(msg) {
+				return
+			}
+		case <-heartbeat:
+			if !c.write(wsMessage{opcode: OpPing}) {
+				return
+			}
+		case <-c.done:
+			for {
+				select {
+				case msg := <-c.queue:
+					if !c.write(msg) {
+						return
+					}
+				default:
+					c.write(wsMessage{opcode: OpClose, data: closePayload(c.closeCode, c.closeReason)})
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *wsConn) write(msg wsMessage) bool {
+	var (
+		data = msg.data
+		rsv1 bool
+		err  error
+	)
+
+	if c.compression && msg.opcode < OpClose && len(data) >= wsCompressThreshold {
+		if data, err = c.compressor.compress(data); err != nil {
+			_ = c.Close()
+			return false
+		}
+		rsv1 = true
+	}
+
+	if c.server.opts.writeTimeout > 0 {
+		_ = c.conn.SetWriteDeadline(time.Now().Add(c.server.opts.writeTimeout))
+	}
+
+	buffers := net.Buffers{appendWsHeader(make([]byte, 0, 10), msg.opcode, len(data), rsv1), data}
+	if _, err = buffers.WriteTo(c.conn); err != nil {
+		_ = c.Close()
+		return false
+	}
+
+	return true
+}
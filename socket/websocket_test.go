@@ -0,0 +1,271 @@
+package socket
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-boot/base/v3/components"
+	"github.com/grpc-boot/base/v3/kind"
+)
+
+type wsClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+func dialWs(t *testing.T, server *httptest.Server, extensions string) (*wsClient, *http.Response) {
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(time.Second * 5))
+
+	request := "GET /ws HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n"
+	if extensions != "" {
+		request += "Sec-WebSocket-Extensions: " + extensions + "\r\n"
+	}
+	_, _ = conn.Write([]byte(request + "\r\n"))
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	return &wsClient{conn: conn, br: br}, resp
+}
+
+func (wc *wsClient) writeFrame(fin bool, rsv1 bool, opcode byte, payload []byte, masked bool) {
+	b0 := opcode
+	if fin {
+		b0 |= 0x80
+	}
+	if rsv1 {
+		b0 |= 0x40
+	}
+
+	header := appendWsHeader(nil, opcode, len(payload), false)
+	header[0] = b0
+
+	data := append([]byte{}, payload...)
+	if masked {
+		header[1] |= 0x80
+		mask := []byte{1, 2, 3, 4}
+		header = append(header, mask...)
+		for i := range data {
+			data[i] ^= mask[i&3]
+		}
+	}
+
+	_, _ = wc.conn.Write(append(header, data...))
+}
+
+func (wc *wsClient) readFrame(t *testing.T) (opcode byte, rsv1 bool, payload []byte) {
+	var header [2]byte
+	if _, err := io.ReadFull(wc.br, header[:]); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	length := int(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		_, _ = io.ReadFull(wc.br, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		_, _ = io.ReadFull(wc.br, ext[:])
+		length = int(binary.BigEndian.Uint64(ext[:]))
+	}
+
+	payload = make([]byte, length)
+	_, _ = io.ReadFull(wc.br, payload)
+	return header[0] & 0x0f, header[0]&0x40 != 0, payload
+}
+
+func newWsTestServer(router *Router, opts ...Option) (*WsServer, *httptest.Server) {
+	ws := NewWsServer(router, opts...)
+	return ws, httptest.NewServer(ws)
+}
+
+func TestWsHandshake(t *testing.T) {
+	if key := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); key != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("want s3pPLMBiTxaQ9kYGzzhZRbK+xOo=, got %s", key)
+	}
+
+	_, server := newWsTestServer(NewRouter(), WithCheckOrigin(func(r *http.Request) bool {
+		return r.Header.Get("Origin") == ""
+	}))
+	defer server.Close()
+
+	client, resp := dialWs(t, server, "permessage-deflate; client_max_window_bits")
+	defer client.conn.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("want 101, got %d", resp.StatusCode)
+	}
+
+	if resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("want accept key, got %s", resp.Header.Get("Sec-WebSocket-Accept"))
+	}
+
+	if !strings.HasPrefix(resp.Header.Get("Sec-WebSocket-Extensions"), wsDeflateName) {
+		t.Fatalf("want %s, got %s", wsDeflateName, resp.Header.Get("Sec-WebSocket-Extensions"))
+	}
+
+	if acceptDeflate([]string{"permessage-deflate; server_max_window_bits=10"}) {
+		t.Fatalf("want false, got true")
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Origin", "http://evil.com")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	forbidden, err := http.DefaultClient.Do(req)
+	if err != nil || forbidden.StatusCode != http.StatusForbidden {
+		t.Fatalf("want 403, got %v %v", forbidden, err)
+	}
+	forbidden.Body.Close()
+}
+
+func TestWsMessage(t *testing.T) {
+	router := NewRouter()
+	router.Handle("echo", func(conn Conn, pkg *components.Package) {
+		count, _ := conn.Ctx().Incr("count")
+		pkg.Param["count"] = count
+		_ = conn.Send(pkg)
+	})
+
+	_, server := newWsTestServer(router, WithHeartbeatInterval(0))
+	defer server.Close()
+
+	client, _ := dialWs(t, server, "permessage-deflate")
+	defer client.conn.Close()
+
+	// 二进制帧分片发送
+	frame, _ := components.DefaultPackageCodec.Encode(&components.Package{Id: 1, Name: "echo", Param: kind.JsonParam{"data": "x"}})
+	client.writeFrame(false, false, OpBinary, frame[:5], true)
+	client.writeFrame(true, false, OpPing, []byte("hi"), true)
+	client.writeFrame(true, false, OpContinuation, frame[5:], true)
+
+	opcode, _, payload := client.readFrame(t)
+	if opcode != OpPong || string(payload) != "hi" {
+		t.Fatalf("want pong hi, got %d %s", opcode, payload)
+	}
+
+	opcode, _, payload = client.readFrame(t)
+	pkg, err := components.DefaultPackageCodec.Decode(payload)
+	if opcode != OpBinary || err != nil || pkg.Id != 1 || pkg.Param.Int("count") != 1 {
+		t.Fatalf("want echo, got %d %v %v", opcode, pkg, err)
+	}
+
+	// 压缩消息，服务端回复同样被压缩
+	text := (&components.Package{Id: 2, Name: "echo", Param: kind.JsonParam{"data": strings.Repeat("abc", 100)}}).Pack()
+	var buf bytes.Buffer
+	fw, _ := flate.NewWriter(&buf, flate.BestCompression)
+	_, _ = fw.Write(text)
+	_ = fw.Flush()
+	client.writeFrame(true, true, OpText, bytes.TrimSuffix(buf.Bytes(), []byte{0, 0, 0xff, 0xff}), true)
+
+	opcode, rsv1, payload := client.readFrame(t)
+	if opcode != OpBinary || !rsv1 {
+		t.Fatalf("want compressed binary, got %d %v", opcode, rsv1)
+	}
+
+	payload, err = wsDecompress(payload, 1<<20)
+	if err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if pkg, err = components.DefaultPackageCodec.Decode(payload); err != nil || pkg.Id != 2 || pkg.Param.Int("count") != 2 {
+		t.Fatalf("want echo, got %v %v", pkg, err)
+	}
+
+	// 关闭握手
+	client.writeFrame(true, false, OpClose, closePayload(4000, "bye"), true)
+	opcode, _, payload = client.readFrame(t)
+	if opcode != OpClose || binary.BigEndian.Uint16(payload) != 4000 {
+		t.Fatalf("want close 4000, got %d %v", opcode, payload)
+	}
+}
+
+func TestWsProtocolError(t *testing.T) {
+	_, server := newWsTestServer(NewRouter(), WithMaxMessageSize(16))
+	defer server.Close()
+
+	cases := []struct {
+		write func(client *wsClient)
+		code  uint16
+	}{
+		{func(client *wsClient) { client.writeFrame(true, false, OpText, []byte("{}"), false) }, CloseProtocolError},
+		{func(client *wsClient) { client.writeFrame(true, false, OpContinuation, []byte("x"), true) }, CloseProtocolError},
+		{func(client *wsClient) { client.writeFrame(true, true, OpText, []byte("x"), true) }, CloseProtocolError},
+		{func(client *wsClient) { client.writeFrame(true, false, OpBinary, make([]byte, 17), true) }, CloseMessageTooBig},
+		{func(client *wsClient) { client.writeFrame(true, false, OpText, []byte{0xff, 0xfe}, true) }, CloseInvalidPayload},
+		{func(client *wsClient) {
+			client.writeFrame(true, false, OpClose, binary.BigEndian.AppendUint16(nil, CloseNoStatus), true)
+		}, CloseProtocolError},
+	}
+
+	for _, c := range cases {
+		client, _ := dialWs(t, server, "")
+		c.write(client)
+
+		opcode, _, payload := client.readFrame(t)
+		if opcode != OpClose || binary.BigEndian.Uint16(payload) != c.code {
+			t.Fatalf("want close %d, got %d %v", c.code, opcode, payload)
+		}
+		client.conn.Close()
+	}
+}
+
+func TestWsShutdown(t *testing.T) {
+	router := NewRouter()
+	ws, server := newWsTestServer(router, WithTextMessage(true))
+	defer server.Close()
+
+	connected := make(chan Conn, 1)
+	ws.On(EventConnect, func(ctx *components.Context) {
+		connected <- ctx.Event().Data().(Conn)
+	})
+
+	client, _ := dialWs(t, server, "")
+	defer client.conn.Close()
+
+	conn := <-connected
+	ws.Join("room", conn)
+	if sent, _ := ws.Broadcast("room", &components.Package{Name: "notice"}); sent != 1 {
+		t.Fatalf("want 1, got %d", sent)
+	}
+
+	opcode, _, payload := client.readFrame(t)
+	pkg := &components.Package{}
+	if opcode != OpText || pkg.Unpack(payload) != nil || pkg.Name != "notice" {
+		t.Fatalf("want notice, got %d %s", opcode, payload)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := ws.ShutdownWithContext(ctx); err != nil {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	opcode, _, payload = client.readFrame(t)
+	if opcode != OpClose || binary.BigEndian.Uint16(payload) != CloseGoingAway {
+		t.Fatalf("want close 1001, got %d %v", opcode, payload)
+	}
+}
@@ -28,6 +28,20 @@ func Base64UrlDecode(src []byte) (dst []byte, err error) {
 	return dst[:end], err
 }
 
+// Base64RawUrlEncode 不带填充的url安全base64编码，用于JWK、JWT
+func Base64RawUrlEncode(src []byte) []byte {
+	dst := make([]byte, base64.RawURLEncoding.EncodedLen(len(src)))
+	base64.RawURLEncoding.Encode(dst, src)
+	return dst
+}
+
+// Base64RawUrlDecode 不带填充的url安全base64解码
+func Base64RawUrlDecode(src []byte) (dst []byte, err error) {
+	dst = make([]byte, base64.RawURLEncoding.DecodedLen(len(src)))
+	end, err := base64.RawURLEncoding.Decode(dst, src)
+	return dst[:end], err
+}
+
 func Base64Encode2String(src []byte) string {
 	return Bytes2String(Base64Encode(src))
 }
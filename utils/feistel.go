@@ -1,5 +1,7 @@
 package utils
 
+import "math/bits"
+
 func FeistelEncrypt64(num, key uint64) uint64 {
 	const rounds = 4
 	var l, r = uint32(num >> 32), uint32(num)
@@ -50,3 +52,55 @@ func FeistelDecrypt32(v, salt uint32) uint32 {
 func FeistelRound16(r, salt, round uint32) uint32 {
 	return ((r ^ salt ^ round) * 0x5bd1e995) & 0xFFFF
 }
+
+// FeistelEncryptBits 对2*halfBits位的数进行Feistel加密，halfBits取值[1, 32]
+func FeistelEncryptBits(num uint64, halfBits uint, key uint64) uint64 {
+	const rounds = 4
+	mask := uint64(1)<<halfBits - 1
+	l, r := (num>>halfBits)&mask, num&mask
+	for i := 0; i < rounds; i++ {
+		l, r = r, l^(uint64(FeistelRound32(uint32(r), key, uint32(i)))&mask)
+	}
+	return (l << halfBits) | r
+}
+
+// FeistelDecryptBits 对2*halfBits位的数进行Feistel解密，halfBits取值[1, 32]
+func FeistelDecryptBits(num uint64, halfBits uint, key uint64) uint64 {
+	const rounds = 4
+	mask := uint64(1)<<halfBits - 1
+	l, r := (num>>halfBits)&mask, num&mask
+	for i := rounds - 1; i >= 0; i-- {
+		l, r = r^(uint64(FeistelRound32(uint32(l), key, uint32(i)))&mask), l
+	}
+	return (l << halfBits) | r
+}
+
+// FeistelCycleEncrypt 使用cycle-walking将[0, max)内的数加密到[0, max)内，num必须小于max
+func FeistelCycleEncrypt(num, max, key uint64) uint64 {
+	halfBits := feistelHalfBits(max)
+	for {
+		num = FeistelEncryptBits(num, halfBits, key)
+		if num < max {
+			return num
+		}
+	}
+}
+
+// FeistelCycleDecrypt FeistelCycleEncrypt的逆运算，num必须小于max
+func FeistelCycleDecrypt(num, max, key uint64) uint64 {
+	halfBits := feistelHalfBits(max)
+	for {
+		num = FeistelDecryptBits(num, halfBits, key)
+		if num < max {
+			return num
+		}
+	}
+}
+
+func feistelHalfBits(max uint64) uint {
+	halfBits := uint(bits.Len64(max-1)+1) / 2
+	if halfBits < 1 {
+		return 1
+	}
+	return halfBits
+}